@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"os"
 	"time"
 
@@ -8,10 +9,17 @@ import (
 )
 
 func main() {
-	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(os.Stdout))
+	level := pocketlog.LevelDebug
+	flag.Var(&level, "level", "The logging threshold, e.g. debug, info, error...")
+	flag.Parse()
+
+	lgr := pocketlog.New(level, pocketlog.WithOutput(os.Stdout))
 	lgr.Infof("A little copying is better than a little dependency.")
 	lgr.Errorf("Errors are values. Documention is for %s.", "users")
 	lgr.Debugf("Make the zero (%d) value useful.", 0)
 
 	lgr.Infof("Hello, %d %v", 2025, time.Now())
+
+	reqLgr := lgr.With(pocketlog.String("request_id", "42"))
+	reqLgr.Info("Clear is better than clever.", pocketlog.Duration("latency", 12*time.Millisecond))
 }
@@ -0,0 +1,149 @@
+package pocketlog
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy tells an asynchronous logger what to do with an entry when its buffer is full.
+type OverflowPolicy byte
+
+const (
+	// OverflowBlock makes the logging call wait until there is room in the buffer.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the entry being logged.
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest entry of the buffer to make room for the new one.
+	OverflowDropOldest
+	// OverflowDropBelowLevel drops the entry being logged if its level is below
+	// the one set with WithAsyncDropLevel, and waits for room otherwise.
+	OverflowDropBelowLevel
+)
+
+// asyncQueue hands entries over to a background goroutine that writes them.
+type asyncQueue struct {
+	entries   chan Entry
+	write     func(Entry)
+	policy    OverflowPolicy
+	dropLevel Level
+
+	// enqueued counts the entries accepted by the queue, processed the ones
+	// written or dropped after having been accepted.
+	enqueued  atomic.Uint64
+	processed atomic.Uint64
+	dropped   atomic.Uint64
+
+	// mu guards closed, and prevents the channel from being closed while sending to it.
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// newAsyncQueue starts the goroutine writing the entries of the queue.
+func newAsyncQueue(size int, policy OverflowPolicy, dropLevel Level, write func(Entry)) *asyncQueue {
+	q := &asyncQueue{
+		entries:   make(chan Entry, max(size, 1)),
+		write:     write,
+		policy:    policy,
+		dropLevel: dropLevel,
+		done:      make(chan struct{}),
+	}
+
+	go q.run()
+
+	return q
+}
+
+// run writes entries until the queue is closed.
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	for e := range q.entries {
+		q.write(e)
+		q.processed.Add(1)
+	}
+}
+
+// enqueue queues the entry, applying the overflow policy if the buffer is full.
+// It returns false if the queue is closed, in which case the caller should write the entry itself.
+func (q *asyncQueue) enqueue(e Entry) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return false
+	}
+
+	// The caller may reuse its fields once the logging call returns.
+	e.Fields = slices.Clone(e.Fields)
+
+	switch {
+	case q.policy == OverflowBlock,
+		q.policy == OverflowDropBelowLevel && e.Level >= q.dropLevel:
+		q.enqueued.Add(1)
+		q.entries <- e
+	case q.policy == OverflowDropOldest:
+		q.enqueued.Add(1)
+		for {
+			select {
+			case q.entries <- e:
+				return true
+			default:
+			}
+
+			select {
+			case <-q.entries:
+				q.dropped.Add(1)
+				q.processed.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case q.entries <- e:
+			q.enqueued.Add(1)
+		default:
+			q.dropped.Add(1)
+		}
+	}
+
+	return true
+}
+
+// flush waits until every entry queued so far has been written or dropped.
+func (q *asyncQueue) flush(ctx context.Context) error {
+	target := q.enqueued.Load()
+
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for q.processed.Load() < target {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+// close flushes the queue and stops its goroutine.
+func (q *asyncQueue) close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.entries)
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
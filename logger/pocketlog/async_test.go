@@ -0,0 +1,292 @@
+package pocketlog_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+// gateWriter holds every Write until the gate is opened.
+type gateWriter struct {
+	started chan struct{}
+	gate    chan struct{}
+
+	mu    sync.Mutex
+	lines []string
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{started: make(chan struct{}, 100), gate: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.started <- struct{}{}
+	<-w.gate
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lines = append(w.lines, strings.TrimSuffix(string(p), "\n"))
+
+	return len(p), nil
+}
+
+func (w *gateWriter) written() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]string(nil), w.lines...)
+}
+
+// newAsyncLogger returns an asynchronous logger whose background goroutine is
+// already stuck writing a first "0" message, so that its buffer can be filled.
+func newAsyncLogger(t *testing.T, w *gateWriter, size int, policy pocketlog.OverflowPolicy, opts ...pocketlog.Option) *pocketlog.Logger {
+	t.Helper()
+
+	opts = append([]pocketlog.Option{
+		pocketlog.WithOutput(w),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithAsync(size, policy),
+	}, opts...)
+	lgr := pocketlog.New(pocketlog.LevelDebug, opts...)
+
+	lgr.Infof("0")
+	<-w.started
+
+	return lgr
+}
+
+func TestLogger_Async(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""), pocketlog.WithAsync(10, pocketlog.OverflowBlock))
+
+	fields := []pocketlog.Field{pocketlog.Int("n", 0)}
+	var expected string
+	for i := range 100 {
+		fields[0] = pocketlog.Int("n", i)
+		lgr.Info(infoMessage, fields...)
+		expected += "I - " + infoMessage + " n=" + strconv.Itoa(i) + "\n"
+	}
+
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+
+	// Entries logged after Close are written synchronously.
+	lgr.Errorf(errorMessage)
+	if !strings.HasSuffix(tw.contents, "E - "+errorMessage+"\n") {
+		t.Errorf("expected the entry to be written after Close, got %q", tw.contents)
+	}
+}
+
+// dispatched returns a hook sending the message of every entry to the channel,
+// before the entry is queued.
+func dispatched(messages chan<- string) pocketlog.Hook {
+	return func(e pocketlog.Entry) (pocketlog.Entry, bool) {
+		messages <- e.Message
+		return e, true
+	}
+}
+
+func TestLogger_AsyncOverflow(t *testing.T) {
+	type testCase struct {
+		policy pocketlog.OverflowPolicy
+		opts   []pocketlog.Option
+		// log must not block: the buffer stays full until it returns.
+		log func(lgr *pocketlog.Logger)
+		// waiting, if set, logs an entry that must wait for room in the buffer.
+		waiting func(lgr *pocketlog.Logger)
+		want    []string
+		dropped uint64
+	}
+
+	tt := map[string]testCase{
+		"drop newest": {
+			policy: pocketlog.OverflowDropNewest,
+			log: func(lgr *pocketlog.Logger) {
+				for i := 1; i <= 5; i++ {
+					lgr.Infof("%d", i)
+				}
+			},
+			want:    []string{"I - 0", "I - 1", "I - 2"},
+			dropped: 3,
+		},
+		"drop oldest": {
+			policy: pocketlog.OverflowDropOldest,
+			log: func(lgr *pocketlog.Logger) {
+				for i := 1; i <= 5; i++ {
+					lgr.Infof("%d", i)
+				}
+			},
+			want:    []string{"I - 0", "I - 4", "I - 5"},
+			dropped: 3,
+		},
+		"drop below level": {
+			policy: pocketlog.OverflowDropBelowLevel,
+			opts:   []pocketlog.Option{pocketlog.WithAsyncDropLevel(pocketlog.LevelInfo)},
+			log: func(lgr *pocketlog.Logger) {
+				lgr.Infof("1")
+				lgr.Infof("2")
+				lgr.Debugf("3")
+				lgr.Debugf("4")
+			},
+			waiting: func(lgr *pocketlog.Logger) {
+				lgr.Infof("5")
+			},
+			want:    []string{"I - 0", "I - 1", "I - 2", "I - 5"},
+			dropped: 2,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			w := newGateWriter()
+			messages := make(chan string, 100)
+			opts := append([]pocketlog.Option{pocketlog.WithHook(dispatched(messages))}, tc.opts...)
+			lgr := newAsyncLogger(t, w, 2, tc.policy, opts...)
+
+			tc.log(lgr)
+
+			if tc.waiting != nil {
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					tc.waiting(lgr)
+				}()
+
+				// Once dispatched, the entry can only be queued after the gate opens.
+				waitForMessage(t, messages, "5")
+				select {
+				case <-done:
+					t.Fatal("expected the logging call to wait for room in the buffer")
+				default:
+				}
+
+				close(w.gate)
+				<-done
+			} else {
+				close(w.gate)
+			}
+
+			if err := lgr.Close(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got := strings.Join(w.written(), ","); got != strings.Join(tc.want, ",") {
+				t.Errorf("invalid lines, expected %q, got %q", tc.want, got)
+			}
+			if got := lgr.Dropped(); got != tc.dropped {
+				t.Errorf("invalid dropped count, expected %d, got %d", tc.dropped, got)
+			}
+		})
+	}
+}
+
+// waitForMessage reads the channel until it receives the message.
+func waitForMessage(t *testing.T, messages <-chan string, message string) {
+	t.Helper()
+
+	for m := range messages {
+		if m == message {
+			return
+		}
+	}
+}
+
+func TestLogger_AsyncBlock(t *testing.T) {
+	w := newGateWriter()
+	messages := make(chan string, 100)
+	lgr := newAsyncLogger(t, w, 1, pocketlog.OverflowBlock, pocketlog.WithHook(dispatched(messages)))
+
+	lgr.Infof("1")
+
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		lgr.Infof("2")
+	}()
+
+	// The buffer is full and the writer is held by the gate: the call blocks.
+	waitForMessage(t, messages, "2")
+	select {
+	case <-logged:
+		t.Fatal("expected the logging call to block while the buffer is full")
+	default:
+	}
+
+	close(w.gate)
+	<-logged
+
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := strings.Join(w.written(), ","); got != "I - 0,I - 1,I - 2" {
+		t.Errorf("invalid lines, got %q", got)
+	}
+	if got := lgr.Dropped(); got != 0 {
+		t.Errorf("expected no dropped entry, got %d", got)
+	}
+}
+
+func TestLogger_AsyncDeadline(t *testing.T) {
+	w := newGateWriter()
+	lgr := newAsyncLogger(t, w, 10, pocketlog.OverflowBlock)
+	defer close(w.gate)
+
+	lgr.Infof("1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := lgr.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Flush to hit the deadline, got %v", err)
+	}
+	if err := lgr.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Close to hit the deadline, got %v", err)
+	}
+}
+
+func TestLogger_SyncFlushClose(t *testing.T) {
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(&testWriter{}))
+
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestLogger_AsyncPanic(t *testing.T) {
+	for name, panics := range map[string]func(lgr *pocketlog.Logger){
+		"Panic":  func(lgr *pocketlog.Logger) { lgr.Panic(errorMessage) },
+		"Panicf": func(lgr *pocketlog.Logger) { lgr.Panicf("%s", errorMessage) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := newGateWriter()
+			close(w.gate)
+			lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(w), pocketlog.WithTimeLayout(""), pocketlog.WithAsync(10, pocketlog.OverflowBlock))
+			defer lgr.Close(context.Background())
+
+			func() {
+				defer func() { _ = recover() }()
+				panics(lgr)
+			}()
+
+			// The entry is written before the panic unwinds.
+			if got := strings.Join(w.written(), ","); got != "P - "+errorMessage {
+				t.Errorf("invalid lines, got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package pocketlog
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+// AtomicLevel is a threshold that can be read and changed concurrently,
+// while loggers are using it.
+type AtomicLevel struct {
+	level atomic.Uint32
+}
+
+// NewAtomicLevel returns an AtomicLevel set to the given level.
+func NewAtomicLevel(level Level) *AtomicLevel {
+	a := &AtomicLevel{}
+	a.SetLevel(level)
+
+	return a
+}
+
+// Level returns the current level.
+func (a *AtomicLevel) Level() Level {
+	return Level(a.level.Load())
+}
+
+// SetLevel changes the level.
+func (a *AtomicLevel) SetLevel(level Level) {
+	a.level.Store(uint32(level))
+}
+
+// Enabled tells whether entries of the given level pass the threshold.
+func (a *AtomicLevel) Enabled(level Level) bool {
+	return level >= a.Level()
+}
+
+// levelPayload is the body of the requests and responses of AtomicLevel.ServeHTTP.
+type levelPayload struct {
+	Level *Level `json:"level,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ServeHTTP reports the current level on GET, and changes it on PUT.
+// Both use a JSON body such as {"level":"info"}; PUT also accepts level
+// letters and numbers, such as {"level":2}.
+func (a *AtomicLevel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var payload levelPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeLevelPayload(w, http.StatusBadRequest, levelPayload{Error: err.Error()})
+			return
+		}
+
+		if payload.Level == nil {
+			writeLevelPayload(w, http.StatusBadRequest, levelPayload{Error: "missing level"})
+			return
+		}
+
+		a.SetLevel(*payload.Level)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeLevelPayload(w, http.StatusMethodNotAllowed, levelPayload{Error: "only GET and PUT are supported"})
+		return
+	}
+
+	level := a.Level()
+	writeLevelPayload(w, http.StatusOK, levelPayload{Level: &level})
+}
+
+// writeLevelPayload writes the payload as the JSON body of the response.
+func writeLevelPayload(w http.ResponseWriter, status int, payload levelPayload) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,132 @@
+package pocketlog_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+func TestLogger_SetLevel(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""))
+	child := lgr.With(pocketlog.Int("n", 1))
+
+	child.Debugf(debugMessage)
+	lgr.SetLevel(pocketlog.LevelDebug)
+	child.Debugf(debugMessage)
+
+	if got := child.Level(); got != pocketlog.LevelDebug {
+		t.Errorf("expected the child to share the level, got %v", got)
+	}
+
+	expected := "D - " + debugMessage + " n=1\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_WithAtomicLevel(t *testing.T) {
+	level := pocketlog.NewAtomicLevel(pocketlog.LevelError)
+	first := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithAtomicLevel(level))
+	second := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithAtomicLevel(level))
+
+	level.SetLevel(pocketlog.LevelWarn)
+
+	if first.Level() != pocketlog.LevelWarn || second.Level() != pocketlog.LevelWarn {
+		t.Errorf("expected both loggers to follow the atomic level, got %v and %v", first.Level(), second.Level())
+	}
+}
+
+func TestLogger_SetLevelConcurrently(t *testing.T) {
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(&testWriter{}))
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for i := range 100 {
+			lgr.SetLevel(pocketlog.Level(i % 3))
+		}
+	})
+	wg.Go(func() {
+		for range 100 {
+			lgr.Debugf(debugMessage)
+		}
+	})
+	wg.Wait()
+}
+
+func TestAtomicLevel_ServeHTTP(t *testing.T) {
+	type testCase struct {
+		method     string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantLevel  pocketlog.Level
+	}
+
+	tt := map[string]testCase{
+		"get": {
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"level":"info"}`,
+			wantLevel:  pocketlog.LevelInfo,
+		},
+		"put": {
+			method:     http.MethodPut,
+			body:       `{"level":"debug"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"level":"debug"}`,
+			wantLevel:  pocketlog.LevelDebug,
+		},
+		"put numeric level": {
+			method:     http.MethodPut,
+			body:       `{"level":1}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"level":"debug"}`,
+			wantLevel:  pocketlog.LevelDebug,
+		},
+		"put unknown level": {
+			method:     http.MethodPut,
+			body:       `{"level":"loud"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"unknown level \"loud\""}`,
+			wantLevel:  pocketlog.LevelInfo,
+		},
+		"put without level": {
+			method:     http.MethodPut,
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"missing level"}`,
+			wantLevel:  pocketlog.LevelInfo,
+		},
+		"post": {
+			method:     http.MethodPost,
+			body:       `{"level":"debug"}`,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   `{"error":"only GET and PUT are supported"}`,
+			wantLevel:  pocketlog.LevelInfo,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			level := pocketlog.NewAtomicLevel(pocketlog.LevelInfo)
+
+			rec := httptest.NewRecorder()
+			level.ServeHTTP(rec, httptest.NewRequest(tc.method, "/level", strings.NewReader(tc.body)))
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("invalid status, expected %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tc.wantBody {
+				t.Errorf("invalid body, expected %s, got %s", tc.wantBody, got)
+			}
+			if got := level.Level(); got != tc.wantLevel {
+				t.Errorf("invalid level, expected %v, got %v", tc.wantLevel, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package pocketlog_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+// wrapper is a library-style wrapper around a pocketlog.Logger.
+type wrapper struct {
+	lgr *pocketlog.Logger
+}
+
+func (w wrapper) Warn(message string) {
+	w.lgr.Errorf("warning: %s", message)
+}
+
+func TestLogger_WithCaller(t *testing.T) {
+	type testCase struct {
+		log func(lgr *pocketlog.Logger) int
+	}
+
+	tt := map[string]testCase{
+		"Infof": {
+			log: func(lgr *pocketlog.Logger) int {
+				lgr.Infof(infoMessage)
+				return currentLine() - 1
+			},
+		},
+		"Info": {
+			log: func(lgr *pocketlog.Logger) int {
+				lgr.Info(infoMessage, pocketlog.Int("n", 1))
+				return currentLine() - 1
+			},
+		},
+		"InfoContext": {
+			log: func(lgr *pocketlog.Logger) int {
+				lgr.InfoContext(context.Background(), infoMessage)
+				return currentLine() - 1
+			},
+		},
+		"child logger": {
+			log: func(lgr *pocketlog.Logger) int {
+				lgr.With(pocketlog.Int("n", 1)).Debugf(debugMessage)
+				return currentLine() - 1
+			},
+		},
+		"wrapper with extra skip": {
+			log: func(lgr *pocketlog.Logger) int {
+				wrapper{lgr: lgr.AddCallerSkip(1)}.Warn(errorMessage)
+				return currentLine() - 1
+			},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithCaller(), pocketlog.WithJSON())
+
+			line := tc.log(lgr)
+
+			var got struct {
+				Caller string `json:"caller"`
+			}
+			if err := json.Unmarshal([]byte(tw.contents), &got); err != nil {
+				t.Fatalf("invalid JSON %q: %s", tw.contents, err)
+			}
+
+			expected := fmt.Sprintf("pocketlog/caller_test.go:%d", line)
+			if got.Caller != expected {
+				t.Errorf("invalid caller, expected %q, got %q", expected, got.Caller)
+			}
+		})
+	}
+}
+
+func TestLogger_WithoutCaller(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw))
+
+	lgr.Infof(infoMessage)
+
+	if strings.Contains(tw.contents, "caller") {
+		t.Errorf("unexpected caller in %q", tw.contents)
+	}
+}
+
+func TestCaller_Function(t *testing.T) {
+	rec := &entryRecorder{}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithFormatter(rec), pocketlog.WithOutput(&testWriter{}), pocketlog.WithCaller())
+
+	lgr.Infof(infoMessage)
+
+	if len(rec.entries) != 1 || rec.entries[0].Caller == nil {
+		t.Fatalf("expected one entry with a caller, got %v", rec.entries)
+	}
+
+	expected := "github.com/pschulze/pocket-sized-go/logger/pocketlog_test.TestCaller_Function"
+	if got := rec.entries[0].Caller.Function; got != expected {
+		t.Errorf("invalid function, expected %q, got %q", expected, got)
+	}
+}
+
+// currentLine returns the line of its caller.
+func currentLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
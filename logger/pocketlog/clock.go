@@ -0,0 +1,47 @@
+package pocketlog
+
+import (
+	"strconv"
+	"time"
+)
+
+// Clock tells the logger what time it is.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function into a Clock.
+type ClockFunc func() time.Time
+
+// Now implements Clock.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// systemClock is the default clock, reading the system time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Timestamp layouts, to be used with WithTimeLayout.
+// Any layout accepted by time.Time.Format can be used as well.
+const (
+	// TimeLayoutRFC3339Nano prints timestamps such as 2006-01-02T15:04:05.999999999Z07:00.
+	TimeLayoutRFC3339Nano = time.RFC3339Nano
+	// TimeLayoutUnixMilli prints timestamps as the number of milliseconds since the Unix epoch.
+	TimeLayoutUnixMilli = "unixmilli"
+)
+
+// formatTime renders t with the given layout.
+func formatTime(t time.Time, layout string) string {
+	switch layout {
+	case TimeLayoutUnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "":
+		return t.Format(TimeLayoutRFC3339Nano)
+	default:
+		return t.Format(layout)
+	}
+}
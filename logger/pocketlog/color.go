@@ -0,0 +1,160 @@
+package pocketlog
+
+import (
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// ColorMode tells a logger whether to colorize its text output.
+type ColorMode byte
+
+const (
+	// ColorAuto colorizes the output if it is a terminal and the NO_COLOR
+	// environment variable is not set.
+	ColorAuto ColorMode = iota
+	// ColorAlways colorizes the output.
+	ColorAlways
+	// ColorNever leaves the output plain.
+	ColorNever
+)
+
+// ANSI escape sequences used by ColorTextFormatter.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiKey     = "\x1b[36m"
+	ansiEscape  = '\x1b'
+	ansiEndMark = 'm'
+)
+
+// levelColors holds the ANSI escape sequence coloring each level.
+var levelColors = [...]string{
+	LevelTrace: "\x1b[90m",
+	LevelDebug: "\x1b[34m",
+	LevelInfo:  "\x1b[32m",
+	LevelWarn:  "\x1b[33m",
+	LevelError: "\x1b[31m",
+	LevelPanic: "\x1b[1;35m",
+	LevelFatal: "\x1b[1;31m",
+}
+
+// ColorTextFormatter writes entries like TextFormatter, with the level colored
+// with ANSI escape sequences, and the field keys too if FieldKeys is set.
+// Escape sequences do not count towards the entry's maximum length.
+type ColorTextFormatter struct {
+	FieldKeys bool
+}
+
+// Format implements Formatter.
+func (f ColorTextFormatter) Format(w io.Writer, e Entry) error {
+	var timestamp string
+	if !e.Time.IsZero() {
+		timestamp = e.FormattedTime() + " "
+	}
+
+	var sb strings.Builder
+	if e.Level <= maxLevel {
+		sb.WriteString(levelColors[e.Level] + e.Level.String() + ansiReset)
+	} else {
+		sb.WriteString(e.Level.String())
+	}
+	sb.WriteString(" - ")
+	if e.LoggerName != "" {
+		sb.WriteString("[" + e.LoggerName + "] ")
+	}
+	sb.WriteString(e.Message)
+	if e.Caller != nil {
+		sb.WriteString(" caller=")
+		sb.WriteString(quoteIfNeeded(e.Caller.String()))
+	}
+	if f.FieldKeys {
+		appendGroupText(&sb, "", e.Fields, ansiKey)
+	} else {
+		appendFieldsText(&sb, e.Fields)
+	}
+
+	_, err := io.WriteString(w, timestamp+truncateANSI(sb.String(), e.maxLen)+"\n")
+	return err
+}
+
+// truncateANSI shortens the message like truncate, leaving its ANSI escape
+// sequences out of the count. The colors are reset if the message is shortened.
+func truncateANSI(message string, maxLen int) string {
+	if maxLen <= 0 || visibleLen(message) <= maxLen-3 {
+		return message
+	}
+
+	var sb strings.Builder
+	visible := 0
+	for i := 0; i < len(message); {
+		if message[i] == ansiEscape {
+			end := strings.IndexByte(message[i:], ansiEndMark)
+			if end >= 0 {
+				sb.WriteString(message[i : i+end+1])
+				i += end + 1
+				continue
+			}
+		}
+
+		if visible == max(maxLen-3, 0) {
+			break
+		}
+
+		_, size := utf8.DecodeRuneInString(message[i:])
+		sb.WriteString(message[i : i+size])
+		i += size
+		visible++
+	}
+	sb.WriteString("..." + ansiReset)
+
+	return sb.String()
+}
+
+// visibleLen returns the number of runes of the message, ANSI escape sequences excluded.
+func visibleLen(message string) int {
+	n := 0
+	for i := 0; i < len(message); {
+		if message[i] == ansiEscape {
+			if end := strings.IndexByte(message[i:], ansiEndMark); end >= 0 {
+				i += end + 1
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(message[i:])
+		i += size
+		n++
+	}
+
+	return n
+}
+
+// colorFormatter swaps text formatters for their colorized counterpart, or
+// the other way round. Other formatters are returned as is.
+func colorFormatter(f Formatter, colored bool) Formatter {
+	switch f.(type) {
+	case TextFormatter:
+		if colored {
+			return ColorTextFormatter{}
+		}
+	case ColorTextFormatter:
+		if !colored {
+			return TextFormatter{}
+		}
+	}
+
+	return f
+}
+
+// colorEnabled tells whether output written to w should be colorized.
+func colorEnabled(mode ColorMode, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	default:
+		return os.Getenv("NO_COLOR") == "" && isTerminal(w)
+	}
+}
@@ -0,0 +1,81 @@
+//go:build linux
+
+package pocketlog_test
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+// openTerminal opens a pseudo-terminal, and returns its controlling side, read
+// by the test, and the terminal itself, written to by the logger.
+func openTerminal(t *testing.T) (ptmx, tty *os.File) {
+	t.Helper()
+
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("no pseudo-terminal: %s", err)
+	}
+	t.Cleanup(func() { _ = ptmx.Close() })
+
+	var unlock int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, ptmx.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); errno != 0 {
+		t.Skipf("cannot unlock the pseudo-terminal: %s", errno)
+	}
+
+	var n uint32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, ptmx.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); errno != 0 {
+		t.Skipf("cannot get the pseudo-terminal number: %s", errno)
+	}
+
+	tty, err = os.OpenFile("/dev/pts/"+strconv.Itoa(int(n)), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("cannot open the pseudo-terminal: %s", err)
+	}
+	t.Cleanup(func() { _ = tty.Close() })
+
+	return ptmx, tty
+}
+
+func TestLogger_WithColor_terminal(t *testing.T) {
+	type testCase struct {
+		noColor  string
+		expected string
+	}
+
+	tt := map[string]testCase{
+		"terminal": {
+			expected: "\x1b[32mI\x1b[0m - " + infoMessage,
+		},
+		"NO_COLOR": {
+			noColor:  "1",
+			expected: "I - " + infoMessage,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tc.noColor)
+			ptmx, tty := openTerminal(t)
+
+			lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tty), pocketlog.WithTimeLayout(""), pocketlog.WithColor(pocketlog.ColorAuto))
+			lgr.Info(infoMessage)
+
+			buf := make([]byte, 256)
+			n, err := ptmx.Read(buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			// The terminal translates the newline into a carriage return and a newline.
+			if got, expected := string(buf[:n]), tc.expected+"\r\n"; got != expected {
+				t.Errorf("invalid contents, expected %q, got %q", expected, got)
+			}
+		})
+	}
+}
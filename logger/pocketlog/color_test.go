@@ -0,0 +1,133 @@
+package pocketlog_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+func TestLogger_WithColor(t *testing.T) {
+	type testCase struct {
+		opts     []pocketlog.Option
+		expected string
+	}
+
+	tt := map[string]testCase{
+		"always": {
+			opts:     []pocketlog.Option{pocketlog.WithColor(pocketlog.ColorAlways)},
+			expected: "\x1b[31mE\x1b[0m - [db] " + errorMessage + " port=8080\n",
+		},
+		"always with field keys": {
+			opts: []pocketlog.Option{
+				pocketlog.WithFormatter(pocketlog.ColorTextFormatter{FieldKeys: true}),
+				pocketlog.WithColor(pocketlog.ColorAlways),
+			},
+			expected: "\x1b[31mE\x1b[0m - [db] " + errorMessage + " \x1b[36mport\x1b[0m=8080\n",
+		},
+		"never": {
+			opts: []pocketlog.Option{
+				pocketlog.WithFormatter(pocketlog.ColorTextFormatter{FieldKeys: true}),
+				pocketlog.WithColor(pocketlog.ColorNever),
+			},
+			expected: "E - [db] " + errorMessage + " port=8080\n",
+		},
+		"auto without a terminal": {
+			opts:     []pocketlog.Option{pocketlog.WithColor(pocketlog.ColorAuto)},
+			expected: "E - [db] " + errorMessage + " port=8080\n",
+		},
+		"other formatters are kept": {
+			opts: []pocketlog.Option{
+				pocketlog.WithFormatter(pocketlog.LogfmtFormatter{}),
+				pocketlog.WithColor(pocketlog.ColorAlways),
+			},
+			expected: "level=error logger=db msg=\"" + errorMessage + "\" port=8080\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			opts := append([]pocketlog.Option{pocketlog.WithOutput(tw), pocketlog.WithTimeLayout("")}, tc.opts...)
+			lgr := pocketlog.New(pocketlog.LevelDebug, opts...).Named("db")
+
+			lgr.Error(errorMessage, pocketlog.Int("port", 8080))
+
+			if tw.contents != tc.expected {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+func TestLogger_WithColor_auto(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	// A pipe is not a terminal.
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(w), pocketlog.WithTimeLayout(""), pocketlog.WithColor(pocketlog.ColorAuto))
+	lgr.Info(infoMessage)
+
+	buf := make([]byte, 64)
+	n, err := r.Read(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "I - " + infoMessage + "\n"
+	if got := string(buf[:n]); got != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, got)
+	}
+}
+
+func TestColorTextFormatter_truncation(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithMaxLen(14),
+		pocketlog.WithFormatter(pocketlog.ColorTextFormatter{FieldKeys: true}),
+	)
+
+	lgr.Info("abc", pocketlog.Int("n", 1))
+	lgr.Info("abcdefg", pocketlog.Int("n", 1))
+
+	// Escape sequences do not count towards the maximum length.
+	expected := "\x1b[32mI\x1b[0m - abc \x1b[36mn\x1b[0m=1\n" +
+		"\x1b[32mI\x1b[0m - abcdefg...\x1b[0m\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_WithColor_noColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	type testCase struct {
+		mode     pocketlog.ColorMode
+		expected string
+	}
+
+	tt := map[string]testCase{
+		"auto": {mode: pocketlog.ColorAuto, expected: "I - " + infoMessage + "\n"},
+		// NO_COLOR only changes the default: colors asked for explicitly are kept.
+		"always": {mode: pocketlog.ColorAlways, expected: "\x1b[32mI\x1b[0m - " + infoMessage + "\n"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""), pocketlog.WithColor(tc.mode))
+
+			lgr.Info(infoMessage)
+
+			if tw.contents != tc.expected {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package pocketlog_test
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+// exclusiveWriter fails the test if Write is called concurrently, and splits
+// what it receives into the lines it was given.
+type exclusiveWriter struct {
+	t       *testing.T
+	writing atomic.Bool
+	writes  []string
+}
+
+func (w *exclusiveWriter) Write(p []byte) (int, error) {
+	if !w.writing.CompareAndSwap(false, true) {
+		w.t.Error("concurrent call to Write")
+		return len(p), nil
+	}
+	defer w.writing.Store(false)
+
+	// Copy byte per byte to widen the window for interleaving.
+	var sb strings.Builder
+	for _, b := range p {
+		sb.WriteByte(b)
+	}
+	w.writes = append(w.writes, sb.String())
+
+	return len(p), nil
+}
+
+func TestLogger_ConcurrentWrites(t *testing.T) {
+	const (
+		goroutines = 20
+		perRoutine = 100
+	)
+
+	w := &exclusiveWriter{t: t}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(w), pocketlog.WithTimeLayout(""))
+
+	var wg sync.WaitGroup
+	for i := range goroutines {
+		wg.Go(func() {
+			// Half the goroutines log through their own child logger.
+			l := lgr
+			if i%2 == 0 {
+				l = lgr.With(pocketlog.Int("goroutine", i))
+			}
+
+			for range perRoutine {
+				l.Info(infoMessage, pocketlog.String("payload", strings.Repeat("x", 100)))
+			}
+		})
+	}
+	wg.Wait()
+
+	if len(w.writes) != goroutines*perRoutine {
+		t.Fatalf("expected %d writes, got %d", goroutines*perRoutine, len(w.writes))
+	}
+
+	for _, write := range w.writes {
+		if !strings.HasPrefix(write, "I - "+infoMessage) || strings.Count(write, "\n") != 1 || !strings.HasSuffix(write, "\n") {
+			t.Fatalf("expected one complete line per write, got %q", write)
+		}
+	}
+}
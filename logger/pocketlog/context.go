@@ -0,0 +1,136 @@
+package pocketlog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// contextKey is the key of the logger stored in a context.
+type contextKey struct{}
+
+// NewContext returns a copy of the context carrying the logger, which FromContext retrieves.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored in the context by NewContext,
+// or the default logger if there is none.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+
+	return Default()
+}
+
+// defaultLogger is the logger set by SetDefault, if any.
+var defaultLogger atomic.Pointer[Logger]
+
+// originalLogger returns the logger Default returns unless SetDefault replaced it.
+var originalLogger = sync.OnceValue(func() *Logger {
+	return New(LevelInfo)
+})
+
+// Default returns the default logger, which FromContext falls back to.
+// Unless replaced with SetDefault, it prints entries of level info or higher to
+// the standard output.
+func Default() *Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+
+	return originalLogger()
+}
+
+// SetDefault makes l the default logger. A nil logger restores the original one.
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// ContextExtractor returns the fields to attach to an entry logged with the context,
+// such as a request ID or trace and span IDs. It returns nil if the context holds none.
+type ContextExtractor func(ctx context.Context) []Field
+
+// ContextValue returns an extractor attaching the value held by the context for
+// ctxKey, if any, as a field named key.
+func ContextValue(key string, ctxKey any) ContextExtractor {
+	return func(ctx context.Context) []Field {
+		v := ctx.Value(ctxKey)
+		if v == nil {
+			return nil
+		}
+
+		return []Field{Any(key, v)}
+	}
+}
+
+// TraceContext prints a message with its fields, and those extracted from the
+// context, if the log level is trace or higher.
+func (l *Logger) TraceContext(ctx context.Context, message string, fields ...Field) {
+	if !l.enabled(LevelTrace) {
+		return
+	}
+
+	l.log(LevelTrace, message, message, l.contextFields(ctx, fields))
+}
+
+// DebugContext prints a message with its fields, and those extracted from the
+// context, if the log level is debug or higher.
+func (l *Logger) DebugContext(ctx context.Context, message string, fields ...Field) {
+	if !l.enabled(LevelDebug) {
+		return
+	}
+
+	l.log(LevelDebug, message, message, l.contextFields(ctx, fields))
+}
+
+// InfoContext prints a message with its fields, and those extracted from the
+// context, if the log level is info or higher.
+func (l *Logger) InfoContext(ctx context.Context, message string, fields ...Field) {
+	if !l.enabled(LevelInfo) {
+		return
+	}
+
+	l.log(LevelInfo, message, message, l.contextFields(ctx, fields))
+}
+
+// WarnContext prints a message with its fields, and those extracted from the
+// context, if the log level is warn or higher.
+func (l *Logger) WarnContext(ctx context.Context, message string, fields ...Field) {
+	if !l.enabled(LevelWarn) {
+		return
+	}
+
+	l.log(LevelWarn, message, message, l.contextFields(ctx, fields))
+}
+
+// ErrorContext prints a message with its fields, and those extracted from the
+// context, if the log level is error or higher.
+func (l *Logger) ErrorContext(ctx context.Context, message string, fields ...Field) {
+	if !l.enabled(LevelError) {
+		return
+	}
+
+	l.log(LevelError, message, message, l.contextFields(ctx, fields))
+}
+
+// contextFields returns the fields extracted from the context, followed by the given ones.
+func (l *Logger) contextFields(ctx context.Context, fields []Field) []Field {
+	if ctx == nil || len(l.extractors) == 0 {
+		return fields
+	}
+
+	var extracted []Field
+	for _, extract := range l.extractors {
+		extracted = append(extracted, extract(ctx)...)
+	}
+
+	if len(extracted) == 0 {
+		return fields
+	}
+
+	return append(extracted, fields...)
+}
@@ -0,0 +1,117 @@
+package pocketlog_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+type requestIDKey struct{}
+
+func TestFromContext(t *testing.T) {
+	lgr := pocketlog.New(pocketlog.LevelDebug)
+
+	ctx := pocketlog.NewContext(context.Background(), lgr)
+	if got := pocketlog.FromContext(ctx); got != lgr {
+		t.Errorf("expected the logger stored in the context, got %p", got)
+	}
+
+	if got := pocketlog.FromContext(context.Background()); got != pocketlog.Default() {
+		t.Errorf("expected the default logger, got %p", got)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	original := pocketlog.Default()
+	t.Cleanup(func() { pocketlog.SetDefault(original) })
+
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""))
+	pocketlog.SetDefault(lgr)
+
+	pocketlog.FromContext(context.Background()).Info(infoMessage)
+
+	expected := "I - " + infoMessage + "\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+
+	pocketlog.SetDefault(nil)
+	if pocketlog.Default() != original {
+		t.Error("expected the original default logger to be restored")
+	}
+}
+
+func TestLogger_WithContextExtractors(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelTrace,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithContextExtractors(
+			pocketlog.ContextValue("request_id", requestIDKey{}),
+			func(ctx context.Context) []pocketlog.Field {
+				return []pocketlog.Field{pocketlog.String("trace_id", "4bf92f35")}
+			},
+		),
+	).With(pocketlog.String("service", "api"))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "r-42")
+
+	lgr.TraceContext(ctx, "trace", pocketlog.Int("n", 1))
+	lgr.DebugContext(ctx, "debug")
+	lgr.InfoContext(ctx, "info")
+	lgr.WarnContext(ctx, "warn")
+	lgr.ErrorContext(ctx, "error")
+	// Fields are only extracted from the contexts holding them.
+	lgr.InfoContext(context.Background(), "no request")
+	// The other methods ignore the extractors.
+	lgr.Info("no context")
+
+	expected := "T - trace service=api request_id=r-42 trace_id=4bf92f35 n=1\n" +
+		"D - debug service=api request_id=r-42 trace_id=4bf92f35\n" +
+		"I - info service=api request_id=r-42 trace_id=4bf92f35\n" +
+		"W - warn service=api request_id=r-42 trace_id=4bf92f35\n" +
+		"E - error service=api request_id=r-42 trace_id=4bf92f35\n" +
+		"I - no request service=api trace_id=4bf92f35\n" +
+		"I - no context service=api\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestSlogHandler_contextExtractors(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithContextExtractors(pocketlog.ContextValue("request_id", requestIDKey{})),
+	)
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "r-42")
+	slog.New(pocketlog.NewSlogHandler(lgr)).With("service", "api").WithGroup("http").InfoContext(ctx, "served", "status", 200)
+
+	expected := "I - served service=api request_id=r-42 http.status=200\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_InfoContext_disabled(t *testing.T) {
+	tw := &testWriter{}
+	extracted := false
+	lgr := pocketlog.New(pocketlog.LevelError,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithContextExtractors(func(context.Context) []pocketlog.Field {
+			extracted = true
+			return nil
+		}),
+	)
+
+	lgr.InfoContext(context.Background(), infoMessage)
+
+	if tw.contents != "" || extracted {
+		t.Errorf("expected nothing to be extracted nor written, got %q", tw.contents)
+	}
+}
@@ -0,0 +1,101 @@
+package pocketlog
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// deduper collapses identical consecutive entries into a single one, followed
+// by a count of repetitions. It is shared by a logger and its children.
+type deduper struct {
+	window time.Duration
+	clock  Clock
+	// emit writes the entries produced by the deduper.
+	emit func(entries []Entry)
+
+	// mu guards the fields below. It is held while emitting, to keep entries in order.
+	mu      sync.Mutex
+	last    *Entry
+	since   time.Time
+	repeats int
+	timer   *time.Timer
+}
+
+// newDeduper returns a deduper, or nil if the window is not positive.
+func newDeduper(window time.Duration, clock Clock, emit func([]Entry)) *deduper {
+	if window <= 0 {
+		return nil
+	}
+
+	return &deduper{window: window, clock: clock, emit: emit}
+}
+
+// dedup tells whether the entry should be written. It returns false for a
+// repetition of the previous entry within the window. Otherwise, it first
+// emits the count of repetitions of the previous entry, if any.
+func (d *deduper) dedup(e Entry) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.clock.Now()
+	if d.last != nil && now.Sub(d.since) < d.window && sameEntry(*d.last, e) {
+		d.repeats++
+		if d.timer == nil {
+			d.timer = time.AfterFunc(d.window, d.flush)
+		}
+
+		return false
+	}
+
+	d.flushLocked()
+
+	e.Fields = slices.Clone(e.Fields)
+	d.last = &e
+	d.since = now
+
+	return true
+}
+
+// flush emits the count of repetitions of the previous entry, if any.
+func (d *deduper) flush() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.flushLocked()
+}
+
+// flushLocked emits the count of repetitions and forgets the previous entry. d.mu must be held.
+func (d *deduper) flushLocked() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+
+	if d.last != nil && d.repeats > 0 {
+		d.emit([]Entry{{
+			Level:      d.last.Level,
+			LoggerName: d.last.LoggerName,
+			Message:    fmt.Sprintf("last message repeated %d times", d.repeats),
+		}})
+	}
+
+	d.last = nil
+	d.repeats = 0
+}
+
+// sameEntry tells whether two entries have the same level, logger, message and fields.
+func sameEntry(a, b Entry) bool {
+	if a.Level != b.Level || a.LoggerName != b.LoggerName || a.Message != b.Message || len(a.Fields) != len(b.Fields) {
+		return false
+	}
+
+	for i := range a.Fields {
+		if a.Fields[i].Key != b.Fields[i].Key || formatValue(a.Fields[i].Value) != formatValue(b.Fields[i].Value) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,111 @@
+package pocketlog_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+func TestLogger_WithDedup(t *testing.T) {
+	tw := &testWriter{}
+	clock := newManualClock()
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(clock),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithDedup(time.Minute),
+	)
+
+	for range 3 {
+		lgr.Error("connection refused", pocketlog.Int("port", 8080))
+	}
+	// Different fields make a different entry.
+	lgr.Error("connection refused", pocketlog.Int("port", 8081))
+	lgr.Error("connection refused", pocketlog.Int("port", 8081))
+	// Once the window is over, the entry is written again.
+	clock.Advance(time.Minute)
+	lgr.Error("connection refused", pocketlog.Int("port", 8081))
+	// A different level makes a different entry.
+	lgr.Warn("connection refused", pocketlog.Int("port", 8081))
+	lgr.Warn("connection refused", pocketlog.Int("port", 8081))
+
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "E - connection refused port=8080\n" +
+		"E - last message repeated 2 times\n" +
+		"E - connection refused port=8081\n" +
+		"E - last message repeated 1 times\n" +
+		"E - connection refused port=8081\n" +
+		"W - connection refused port=8081\n" +
+		"W - last message repeated 1 times\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_WithDedup_children(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(newManualClock()),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithDedup(time.Minute),
+	)
+
+	lgr.Info(infoMessage)
+	lgr.With(pocketlog.String("user", "ada")).Info(infoMessage)
+	lgr.Named("db").Info(infoMessage)
+	lgr.Named("db").Info(infoMessage)
+
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "I - " + infoMessage + "\n" +
+		"I - " + infoMessage + " user=ada\n" +
+		"I - [db] " + infoMessage + "\n" +
+		"I - [db] last message repeated 1 times\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+// notifyingWriter sends each write to a channel.
+type notifyingWriter chan string
+
+func (w notifyingWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestLogger_WithDedup_timer(t *testing.T) {
+	w := make(notifyingWriter, 10)
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(w),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithDedup(20*time.Millisecond),
+	)
+
+	for range 3 {
+		lgr.Info(infoMessage)
+	}
+
+	expected := []string{
+		"I - " + infoMessage + "\n",
+		"I - last message repeated 2 times\n",
+	}
+	for _, line := range expected {
+		select {
+		case got := <-w:
+			if got != line {
+				t.Errorf("invalid line, expected %q, got %q", line, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %q", line)
+		}
+	}
+}
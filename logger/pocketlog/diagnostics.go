@@ -0,0 +1,126 @@
+package pocketlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrorHandler is called when an entry could not be written, with the errors
+// of the failing sinks and, if any, of the fallback output, and when a hook
+// panics. It must not log through the same logger, or it may never return.
+type ErrorHandler func(e Entry, err error)
+
+// WriteStats holds the counters of write failures and hook panics of a logger.
+type WriteStats struct {
+	// Failed is the number of entries at least one sink failed to write.
+	Failed uint64
+	// Fallback is the number of failed entries written to the fallback output.
+	Fallback uint64
+	// FallbackFailed is the number of failed entries the fallback output could not write either.
+	FallbackFailed uint64
+	// HookPanics is the number of times a hook panicked.
+	HookPanics uint64
+}
+
+// diagnostics handles write failures. It is shared by a logger and its children.
+type diagnostics struct {
+	handler   ErrorHandler
+	formatter Formatter
+
+	// fallbackMu serializes writes to fallback.
+	fallbackMu sync.Mutex
+	fallback   io.Writer
+
+	failed         atomic.Uint64
+	fallbackOK     atomic.Uint64
+	fallbackFailed atomic.Uint64
+	hookPanics     atomic.Uint64
+}
+
+// writeFailed counts the failure, writes the entry to the fallback output, if
+// any, and reports the error to the handler, if any.
+func (d *diagnostics) writeFailed(e Entry, err error) {
+	d.failed.Add(1)
+
+	if d.fallback != nil {
+		if ferr := d.writeFallback(e); ferr != nil {
+			d.fallbackFailed.Add(1)
+			err = errors.Join(err, fmt.Errorf("fallback output: %w", ferr))
+		} else {
+			d.fallbackOK.Add(1)
+		}
+	}
+
+	d.report(e, err)
+}
+
+// hookPanicked counts the panic of a hook, and reports it to the handler, if any.
+func (d *diagnostics) hookPanicked(e Entry, err error) {
+	d.hookPanics.Add(1)
+	d.report(e, err)
+}
+
+// report hands the error to the handler, if any.
+func (d *diagnostics) report(e Entry, err error) {
+	if d.handler != nil {
+		d.handler(e, err)
+	}
+}
+
+// writeFallback formats the entry to the fallback output.
+func (d *diagnostics) writeFallback(e Entry) error {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer putBuffer(buf)
+
+	if err := d.formatter.Format(buf, e); err != nil {
+		return err
+	}
+
+	d.fallbackMu.Lock()
+	defer d.fallbackMu.Unlock()
+
+	return writeFull(d.fallback, buf.Bytes())
+}
+
+// stats returns the counters of write failures and hook panics.
+func (d *diagnostics) stats() WriteStats {
+	return WriteStats{
+		Failed:         d.failed.Load(),
+		Fallback:       d.fallbackOK.Load(),
+		FallbackFailed: d.fallbackFailed.Load(),
+		HookPanics:     d.hookPanics.Load(),
+	}
+}
+
+// maxStalledWrites is the number of consecutive writes of nothing after which
+// writeFull gives up.
+const maxStalledWrites = 3
+
+// writeFull writes p to w, writing the rest again after a short write, as long
+// as the writer makes progress.
+func writeFull(w io.Writer, p []byte) error {
+	stalled := 0
+	for {
+		n, err := w.Write(p)
+		n = min(max(n, 0), len(p))
+		p = p[n:]
+
+		switch {
+		case len(p) == 0:
+			return err
+		case n == 0 && err != nil:
+			return err
+		case n == 0:
+			stalled++
+			if stalled == maxStalledWrites {
+				return io.ErrShortWrite
+			}
+		default:
+			stalled = 0
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package pocketlog_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+// shortWriter writes at most limit bytes per call, without reporting an error.
+type shortWriter struct {
+	limit int
+	calls int
+	tw    testWriter
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	w.calls++
+	p = p[:min(len(p), w.limit)]
+	return w.tw.Write(p)
+}
+
+// stalledWriter writes nothing, without reporting an error.
+type stalledWriter struct{}
+
+func (stalledWriter) Write([]byte) (int, error) {
+	return 0, nil
+}
+
+func TestLogger_WithErrorHandler(t *testing.T) {
+	var failures []string
+	fallback := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(failingWriter{}),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithFallbackOutput(fallback),
+		pocketlog.WithErrorHandler(func(e pocketlog.Entry, err error) {
+			failures = append(failures, e.Message+": "+err.Error())
+		}),
+	)
+
+	lgr.Info(infoMessage)
+	lgr.Named("db").Errorf(errorMessage)
+
+	expected := "I - " + infoMessage + "\nE - [db] " + errorMessage + "\n"
+	if fallback.contents != expected {
+		t.Errorf("invalid fallback contents, expected %q, got %q", expected, fallback.contents)
+	}
+
+	expectedFailures := []string{infoMessage + ": disk full", errorMessage + ": disk full"}
+	if strings.Join(failures, "\n") != strings.Join(expectedFailures, "\n") {
+		t.Errorf("invalid failures, expected %q, got %q", expectedFailures, failures)
+	}
+
+	expectedStats := pocketlog.WriteStats{Failed: 2, Fallback: 2}
+	if got := lgr.WriteStats(); got != expectedStats {
+		t.Errorf("invalid stats, expected %+v, got %+v", expectedStats, got)
+	}
+}
+
+func TestLogger_WithFallbackOutput_failing(t *testing.T) {
+	var failure error
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(failingWriter{}),
+		pocketlog.WithFallbackOutput(stalledWriter{}),
+		pocketlog.WithErrorHandler(func(_ pocketlog.Entry, err error) {
+			failure = err
+		}),
+	)
+
+	lgr.Info(infoMessage)
+
+	if !errors.Is(failure, io.ErrShortWrite) || !strings.Contains(failure.Error(), "disk full") {
+		t.Errorf("expected the errors of both outputs, got %v", failure)
+	}
+
+	expectedStats := pocketlog.WriteStats{Failed: 1, FallbackFailed: 1}
+	if got := lgr.WriteStats(); got != expectedStats {
+		t.Errorf("invalid stats, expected %+v, got %+v", expectedStats, got)
+	}
+}
+
+func TestLogger_WithErrorHandler_asyncSink(t *testing.T) {
+	var mu sync.Mutex
+	var failures []string
+	fallback := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithSinks(pocketlog.NewSink(failingWriter{}, pocketlog.SinkAsync(10, pocketlog.OverflowBlock))),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithFallbackOutput(fallback),
+		pocketlog.WithErrorHandler(func(e pocketlog.Entry, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures = append(failures, e.Message+": "+err.Error())
+		}),
+	)
+
+	lgr.Info(infoMessage)
+
+	// The sink fails in the background, after the entry was queued.
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if expected := infoMessage + ": disk full"; strings.Join(failures, "\n") != expected {
+		t.Errorf("invalid failures, expected %q, got %q", expected, failures)
+	}
+	if expected := "I - " + infoMessage + "\n"; fallback.contents != expected {
+		t.Errorf("invalid fallback contents, expected %q, got %q", expected, fallback.contents)
+	}
+
+	expectedStats := pocketlog.WriteStats{Failed: 1, Fallback: 1}
+	if got := lgr.WriteStats(); got != expectedStats {
+		t.Errorf("invalid stats, expected %+v, got %+v", expectedStats, got)
+	}
+}
+
+func TestLogger_WriteStats_withoutHandler(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSinks(
+		pocketlog.NewSink(failingWriter{}),
+		pocketlog.NewSink(tw),
+	))
+
+	lgr.Info(infoMessage)
+	lgr.With(pocketlog.Int("n", 1)).Info(infoMessage)
+
+	// Failures are counted across child loggers, even when another sink succeeds.
+	expectedStats := pocketlog.WriteStats{Failed: 2}
+	if got := lgr.WriteStats(); got != expectedStats {
+		t.Errorf("invalid stats, expected %+v, got %+v", expectedStats, got)
+	}
+}
+
+func TestLogger_shortWrites(t *testing.T) {
+	w := &shortWriter{limit: 4}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(w), pocketlog.WithTimeLayout(""))
+
+	lgr.Info(infoMessage)
+
+	expected := "I - " + infoMessage + "\n"
+	if w.tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, w.tw.contents)
+	}
+
+	if expectedCalls := (len(expected) + 3) / 4; w.calls != expectedCalls {
+		t.Errorf("expected %d calls to Write, got %d", expectedCalls, w.calls)
+	}
+
+	if got := lgr.WriteStats(); got != (pocketlog.WriteStats{}) {
+		t.Errorf("expected no failure, got %+v", got)
+	}
+}
@@ -2,13 +2,102 @@
 Package pocketlog exposes an API to log your work.
 
 First, instantiate a logger with pocketlog.New, passing it a threshold log level.
-Messages of lesser criticality will not be logged.
+Messages of lesser criticality will not be logged. The threshold can be changed
+at any time with Logger.SetLevel, or through the HTTP handler of an AtomicLevel
+shared with WithAtomicLevel.
 
-Sharing the logger is the responsibility of the caller.
+A Logger is safe for concurrent use: each entry is written to the output as one
+complete line, with a single call to Write, and writes are serialized across the
+logger and the child loggers derived from it. Loggers created by separate calls
+to New do not coordinate, even when they share an output.
 
-The logger can be called to log messages on three levels of criticality:
+WithAsync moves writes off the logging call: entries are queued in a bounded
+buffer and written by a background goroutine. An OverflowPolicy decides whether
+a full buffer blocks the caller or drops entries, which Logger.Dropped counts.
+Logger.Flush and Logger.Close wait for queued entries to be written.
+
+WithHook registers functions that receive entries of chosen levels before they
+are written, and can inspect, enrich, rewrite or drop them, e.g. to count errors
+or filter out health checks. A hook that panics is skipped, and counted by
+Logger.WriteStats.
+
+WithSampling and WithRateLimit keep noisy call sites in check: the former writes
+the first entries of each message template per interval then every Nth one, the
+latter caps the rate of a level. Warnings summarize the suppressed entries.
+
+WithDedup collapses identical consecutive entries: the first one is written, and
+its repetitions within a window are replaced by a "last message repeated N times"
+line.
+
+NewSlogHandler returns a log/slog handler writing to a Logger, so that code using
+the standard structured logging API ends up in the same output. Conversely,
+WithSlogHandler makes a Logger emit its entries through any slog.Handler.
+
+NewStdLogger and NewStdWriter bridge the standard library log package into a
+Logger, e.g. for http.Server.ErrorLog, and RedirectStdLog does so for the
+package-level functions such as log.Printf.
+
+A Logger writes to its output by default. WithSinks replaces it with several
+sinks, each created by NewSink with its own output, minimum level, formatter and,
+optionally, background goroutine. A failing sink doesn't prevent the others from
+receiving entries. Failures are counted by Logger.WriteStats, and can be reported
+with WithErrorHandler and written to another output with WithFallbackOutput.
+
+NewSyslogSink returns a sink sending entries to a syslog server over UDP, TCP or
+a unix socket, as RFC 5424 messages carrying the fields as structured data, or
+as RFC 3164 ones.
+
+NewNetworkSink returns a sink shipping formatted entries to a collector over TCP,
+UDP or a unix socket. It buffers entries while the collector is down, reconnects
+with an exponential backoff, and reports its counters with NetworkSink.Stats.
+
+NewRotatingFile returns an output for long-running programs: a file rotated by
+size and/or time, whose backups can be compressed and pruned by count and age,
+and which can be reopened on SIGHUP for logrotate.
+
+The logger can be called to log messages on seven levels of criticality:
+  - Trace: used to follow the execution of the code step by step.
   - Debug: used to log messages for debugging code during development.
   - Info: used to log general information about the program's execution.
+  - Warn: used to log unexpected events the program can recover from.
   - Error: used to log errors that occur during execution.
+  - Panic: used to log an error, after which the logger panics.
+  - Fatal: used to log an error, after which the program exits.
+
+Persist levels by name, with Level.MarshalText and ParseLevel, rather than by
+number: see Level for how their values changed.
+
+Each level has a printf-style method, such as Infof, and a structured method,
+such as Info, that takes a message and typed key/value fields:
+
+	lgr.Info("request served", pocketlog.String("path", "/"), pocketlog.Duration("latency", d))
+
+Logger.With returns a child logger that adds its fields to every line.
+
+NewContext and FromContext carry a Logger through a context.Context, FromContext
+falling back to Default. Methods such as InfoContext attach the fields returned by
+the extractors of WithContextExtractors, e.g. a request or trace ID.
+
+WithRedactedKeys masks the values of sensitive fields, such as "password", and
+WithRedactedPatterns replaces sensitive data, such as emails or credit card
+numbers, in messages and values. Types implementing Redactor control how they are
+logged.
+
+Logger.Named returns a child logger whose dotted name, such as "db.pool", is
+printed on every line. With WithLevelRegistry, named loggers take their threshold
+from a LevelRegistry, set per name prefix from a spec such as "db=debug,http=error".
+
+Lines are printed as text by default. Use WithFormatter to pick another
+encoding: the package ships TextFormatter, LogfmtFormatter and JSONFormatter,
+and any type implementing Formatter can be used. WithColor colors the levels of
+text lines, by default only when printing to a terminal and NO_COLOR is not set.
+
+Every entry is timestamped. WithTimeLayout, WithUTC and WithLocalTime control how
+the time is printed, and WithClock replaces the system clock, e.g. in tests.
+
+WithCaller records the file, line and function of each logging call. Code wrapping
+a Logger should use Logger.AddCallerSkip so that its users' call sites are reported.
+
+The pocketlogtest package records the entries of a Logger for assertions in tests.
 */
 package pocketlog
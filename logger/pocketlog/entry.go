@@ -0,0 +1,83 @@
+package pocketlog
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// Entry is a single log event, as handed to a Formatter.
+type Entry struct {
+	Level Level
+	// LoggerName is the dotted name of the logger, empty for unnamed loggers.
+	LoggerName string
+	Message    string
+	// Fields holds the logger's fields followed by the ones passed to the logging call.
+	Fields []Field
+	// Time is zero if timestamps are disabled.
+	Time time.Time
+	// Caller is nil unless the logger records call sites.
+	Caller *Caller
+
+	maxLen     int
+	timeLayout string
+	// template is the format of a printf-style message, or the message itself.
+	template string
+}
+
+// MaxLen returns the maximum length, in runes, the logger allows for the entry.
+// Zero means there is no limit.
+func (e Entry) MaxLen() int {
+	return e.maxLen
+}
+
+// FormattedTime returns the entry's time printed with the logger's layout,
+// or an empty string if the entry has no time.
+func (e Entry) FormattedTime() string {
+	if e.Time.IsZero() {
+		return ""
+	}
+
+	return formatTime(e.Time, e.timeLayout)
+}
+
+// Caller locates the call site of a logging call.
+type Caller struct {
+	File     string
+	Line     int
+	Function string
+
+	// pc is the program counter of the call site, zero if unknown.
+	pc uintptr
+}
+
+// String returns the caller as file:line, the file being shortened to its
+// directory and base name.
+func (c Caller) String() string {
+	file := c.File
+	if dir := filepath.Dir(file); dir != "." {
+		file = filepath.Join(filepath.Base(dir), filepath.Base(file))
+	}
+
+	return file + ":" + strconv.Itoa(c.Line)
+}
+
+// callerAt returns the caller skip frames above its own caller,
+// or nil if the stack is not that deep.
+func callerAt(skip int) *Caller {
+	var pcs [1]uintptr
+	// +2 skips runtime.Callers and callerAt.
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return nil
+	}
+
+	return callerFromPC(pcs[0])
+}
+
+// callerFromPC returns the caller at the given program counter.
+func callerFromPC(pc uintptr) *Caller {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	return &Caller{File: frame.File, Line: frame.Line, Function: frame.Function, pc: pc}
+}
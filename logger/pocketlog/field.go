@@ -0,0 +1,142 @@
+package pocketlog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Field is a key/value pair attached to a log entry.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// String returns a field holding a string value.
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int returns a field holding an int value.
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int64 returns a field holding an int64 value.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Uint64 returns a field holding an uint64 value.
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Float64 returns a field holding a float64 value.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool returns a field holding a boolean value.
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Duration returns a field holding a time.Duration value.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Time returns a field holding a time.Time value.
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err returns a field holding an error, under the "error" key.
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// Group returns a field holding nested fields. Formatters print them as an
+// object, or with dotted keys such as "key.nested".
+func Group(key string, fields ...Field) Field {
+	return Field{Key: key, Value: fields}
+}
+
+// Any returns a field holding any value.
+func Any(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// formatValue renders a field value as plain text.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// quoteIfNeeded quotes a text value if it would be ambiguous in a key=value list.
+func quoteIfNeeded(s string) string {
+	if s == "" {
+		return `""`
+	}
+
+	for _, r := range s {
+		if r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+
+	return s
+}
+
+// appendFieldsText appends fields to the builder as space-separated key=value pairs.
+// Groups are flattened, their keys prefixing those of their fields.
+func appendFieldsText(sb *strings.Builder, fields []Field) {
+	appendGroupText(sb, "", fields, "")
+}
+
+// appendGroupText appends fields with their keys prefixed, and colored with
+// the ANSI escape sequence keyColor unless it is empty.
+func appendGroupText(sb *strings.Builder, prefix string, fields []Field, keyColor string) {
+	for _, f := range fields {
+		if group, ok := f.Value.([]Field); ok {
+			appendGroupText(sb, prefix+f.Key+".", group, keyColor)
+			continue
+		}
+
+		sb.WriteByte(' ')
+		if keyColor != "" {
+			sb.WriteString(keyColor + quoteIfNeeded(prefix+f.Key) + ansiReset)
+		} else {
+			sb.WriteString(quoteIfNeeded(prefix + f.Key))
+		}
+		sb.WriteByte('=')
+		sb.WriteString(quoteIfNeeded(formatValue(f.Value)))
+	}
+}
@@ -0,0 +1,134 @@
+package pocketlog
+
+import (
+	"io"
+	"strings"
+)
+
+// Formatter encodes entries.
+// Format is called once per entry and must write the whole encoding, including
+// the trailing newline. It may be called concurrently.
+type Formatter interface {
+	Format(w io.Writer, e Entry) error
+}
+
+// TextFormatter writes entries as human-readable lines, such as:
+//
+//	2006-01-02T15:04:05Z I - [db.pool] message key=value
+//
+// The line, timestamp excluded, is truncated to the entry's maximum length.
+type TextFormatter struct{}
+
+// Format implements Formatter.
+func (TextFormatter) Format(w io.Writer, e Entry) error {
+	var timestamp string
+	if !e.Time.IsZero() {
+		timestamp = e.FormattedTime() + " "
+	}
+
+	var sb strings.Builder
+	sb.WriteString(e.Level.String())
+	sb.WriteString(" - ")
+	if e.LoggerName != "" {
+		sb.WriteString("[" + e.LoggerName + "] ")
+	}
+	sb.WriteString(e.Message)
+	if e.Caller != nil {
+		sb.WriteString(" caller=")
+		sb.WriteString(quoteIfNeeded(e.Caller.String()))
+	}
+	appendFieldsText(&sb, e.Fields)
+
+	_, err := io.WriteString(w, timestamp+truncate(sb.String(), e.maxLen)+"\n")
+	return err
+}
+
+// LogfmtFormatter writes entries as logfmt lines, such as:
+//
+//	time=2006-01-02T15:04:05Z level=info msg="a message" key=value
+//
+// Only the message is truncated to the entry's maximum length.
+type LogfmtFormatter struct{}
+
+// Format implements Formatter.
+func (LogfmtFormatter) Format(w io.Writer, e Entry) error {
+	var sb strings.Builder
+	if !e.Time.IsZero() {
+		sb.WriteString("time=")
+		sb.WriteString(quoteIfNeeded(e.FormattedTime()))
+		sb.WriteByte(' ')
+	}
+	sb.WriteString("level=")
+	sb.WriteString(e.Level.Name())
+	if e.LoggerName != "" {
+		sb.WriteString(" logger=")
+		sb.WriteString(quoteIfNeeded(e.LoggerName))
+	}
+	sb.WriteString(" msg=")
+	sb.WriteString(quoteIfNeeded(truncate(e.Message, e.maxLen)))
+	if e.Caller != nil {
+		sb.WriteString(" caller=")
+		sb.WriteString(quoteIfNeeded(e.Caller.String()))
+	}
+	appendFieldsText(&sb, e.Fields)
+	sb.WriteByte('\n')
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// JSONFormatter writes entries as one JSON object per line, such as:
+//
+//	{"time":"2006-01-02T15:04:05Z","level":"info","msg":"a message","key":"value"}
+//
+// Control characters are escaped and invalid UTF-8 is replaced by U+FFFD.
+// Only the message is truncated to the entry's maximum length, so that the
+// line remains valid JSON.
+type JSONFormatter struct{}
+
+// Format implements Formatter.
+func (JSONFormatter) Format(w io.Writer, e Entry) error {
+	buf := make([]byte, 0, 256)
+	buf = append(buf, '{')
+	if !e.Time.IsZero() {
+		buf = append(buf, `"time":`...)
+		if e.timeLayout == TimeLayoutUnixMilli {
+			buf = append(buf, e.FormattedTime()...)
+		} else {
+			buf = appendJSONString(buf, e.FormattedTime())
+		}
+		buf = append(buf, ',')
+	}
+	buf = append(buf, `"level":`...)
+	buf = appendJSONString(buf, e.Level.Name())
+	if e.LoggerName != "" {
+		buf = append(buf, `,"logger":`...)
+		buf = appendJSONString(buf, e.LoggerName)
+	}
+	buf = append(buf, `,"msg":`...)
+	buf = appendJSONString(buf, truncate(e.Message, e.maxLen))
+	if e.Caller != nil {
+		buf = append(buf, `,"caller":`...)
+		buf = appendJSONString(buf, e.Caller.String())
+	}
+	buf = appendJSONFields(buf, e.Fields)
+	buf = append(buf, '}', '\n')
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// truncate shortens the message to maxLen runes, ending it with an ellipsis.
+// A maxLen of zero or less means no limit.
+func truncate(message string, maxLen int) string {
+	if maxLen <= 0 {
+		return message
+	}
+
+	runes := []rune(message)
+	if len(runes) <= maxLen-3 {
+		return message
+	}
+
+	return string(runes[:max(maxLen-3, 0)]) + "..."
+}
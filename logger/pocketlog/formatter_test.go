@@ -0,0 +1,152 @@
+package pocketlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+// testFormatterConformance runs the checks every built-in formatter must pass.
+// levelID is how the formatter renders pocketlog.LevelInfo.
+func testFormatterConformance(t *testing.T, f pocketlog.Formatter, levelID string) {
+	t.Helper()
+
+	entry := pocketlog.Entry{
+		Level:   pocketlog.LevelInfo,
+		Time:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: infoMessage,
+		Fields: []pocketlog.Field{
+			pocketlog.String("user", "gopher"),
+			pocketlog.Int("attempt", 3),
+		},
+	}
+
+	format := func(t *testing.T, e pocketlog.Entry) string {
+		t.Helper()
+
+		var buf bytes.Buffer
+		if err := f.Format(&buf, e); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return buf.String()
+	}
+
+	t.Run("single line", func(t *testing.T) {
+		got := format(t, entry)
+		if !strings.HasSuffix(got, "\n") || strings.Count(got, "\n") != 1 {
+			t.Errorf("expected exactly one trailing newline, got %q", got)
+		}
+	})
+
+	t.Run("level message and fields", func(t *testing.T) {
+		got := format(t, entry)
+		for _, want := range []string{levelID, infoMessage, "user", "gopher", "attempt", "3"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected %q in %q", want, got)
+			}
+		}
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		if first, second := format(t, entry), format(t, entry); first != second {
+			t.Errorf("expected identical outputs, got %q and %q", first, second)
+		}
+	})
+
+	t.Run("caller", func(t *testing.T) {
+		e := entry
+		e.Caller = &pocketlog.Caller{File: "main.go", Line: 12, Function: "main.main"}
+		if got := format(t, e); !strings.Contains(got, "main.go:12") {
+			t.Errorf("expected caller in %q", got)
+		}
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				var buf bytes.Buffer
+				_ = f.Format(&buf, entry)
+			})
+		}
+		wg.Wait()
+	})
+}
+
+func TestFormatters_Conformance(t *testing.T) {
+	type testCase struct {
+		formatter pocketlog.Formatter
+		levelID   string
+	}
+
+	tt := map[string]testCase{
+		"text":   {formatter: pocketlog.TextFormatter{}, levelID: "I"},
+		"logfmt": {formatter: pocketlog.LogfmtFormatter{}, levelID: "level=info"},
+		"json":   {formatter: pocketlog.JSONFormatter{}, levelID: `"level":"info"`},
+		"color":  {formatter: pocketlog.ColorTextFormatter{FieldKeys: true}, levelID: "\x1b[32mI\x1b[0m"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			testFormatterConformance(t, tc.formatter, tc.levelID)
+		})
+	}
+}
+
+func TestLogfmtFormatter(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithFormatter(pocketlog.LogfmtFormatter{}))
+
+	lgr.Error("disk full\nretrying", pocketlog.String("path", "/var/log"), pocketlog.String("reason", "no space"))
+
+	_, line, found := strings.Cut(tw.contents, " ")
+	if !found || !strings.HasPrefix(tw.contents, "time=") {
+		t.Fatalf("expected a leading time, got %q", tw.contents)
+	}
+
+	expected := `level=error msg="disk full\nretrying" path=/var/log reason="no space"` + "\n"
+	if line != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, line)
+	}
+}
+
+func TestWithFormatter_Custom(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithFormatter(compactFormatter{}))
+
+	lgr.With(pocketlog.Int("n", 1)).Infof("hello %s", "world")
+
+	expected := `["info","hello world",1]` + "\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+// compactFormatter is a custom formatter writing entries as JSON arrays.
+type compactFormatter struct{}
+
+func (compactFormatter) Format(w io.Writer, e pocketlog.Entry) error {
+	values := []any{e.Level.Name(), e.Message}
+	for _, f := range e.Fields {
+		values = append(values, f.Value)
+	}
+
+	return json.NewEncoder(w).Encode(values)
+}
+
+// entryRecorder is a formatter that keeps the entries it is given.
+type entryRecorder struct {
+	entries []pocketlog.Entry
+}
+
+func (r *entryRecorder) Format(_ io.Writer, e pocketlog.Entry) error {
+	r.entries = append(r.entries, e)
+	return nil
+}
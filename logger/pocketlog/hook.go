@@ -0,0 +1,59 @@
+package pocketlog
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Hook processes an entry before it is sampled and written. It returns the
+// entry to write, which it may have enriched or rewritten, and false to drop
+// it instead. A hook must not modify the elements of the entry's fields: it
+// can append to them, or replace the slice.
+type Hook func(e Entry) (Entry, bool)
+
+// registeredHook is a hook and the levels of the entries it processes.
+type registeredHook struct {
+	hook Hook
+	// levels has the bit 1<<level set for each level the hook is registered for.
+	levels uint8
+}
+
+// runHooks runs the hooks registered for the entry's level, in registration
+// order, until one drops it. A hook panicking is skipped, and the panic is
+// counted in the write stats and reported to the error handler, if any.
+func (l *Logger) runHooks(e Entry) (Entry, bool) {
+	// Appending to the fields of the entry must not modify those of the logger.
+	e.Fields = slices.Clip(e.Fields)
+
+	for _, h := range l.hooks {
+		if h.levels&(1<<e.Level) == 0 {
+			continue
+		}
+
+		out, keep, err := runHook(h.hook, e)
+		if err != nil {
+			l.diag.hookPanicked(e, err)
+			continue
+		}
+		if !keep {
+			return out, false
+		}
+
+		e = out
+	}
+
+	return e, true
+}
+
+// runHook runs the hook, turning a panic into an error.
+func runHook(hook Hook, e Entry) (out Entry, keep bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("hook panicked: %v", r)
+		}
+	}()
+
+	out, keep = hook(e)
+
+	return out, keep, nil
+}
@@ -0,0 +1,138 @@
+package pocketlog_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+func TestLogger_WithHook(t *testing.T) {
+	tw := &testWriter{}
+	errorCount := 0
+	var order []string
+
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithTimeLayout(""),
+		// Counts errors.
+		pocketlog.WithHook(func(e pocketlog.Entry) (pocketlog.Entry, bool) {
+			errorCount++
+			order = append(order, "count")
+			return e, true
+		}, pocketlog.LevelError),
+		// Drops health checks.
+		pocketlog.WithHook(func(e pocketlog.Entry) (pocketlog.Entry, bool) {
+			order = append(order, "filter")
+			return e, e.Message != "GET /healthz"
+		}),
+		// Enriches and rewrites entries.
+		pocketlog.WithHook(func(e pocketlog.Entry) (pocketlog.Entry, bool) {
+			order = append(order, "enrich")
+			e.Fields = append(e.Fields, pocketlog.String("region", "eu-west-1"))
+			e.Message = strings.ToUpper(e.Message[:1]) + e.Message[1:]
+			return e, true
+		}, pocketlog.LevelInfo, pocketlog.LevelError),
+	)
+
+	lgr.Info("GET /healthz")
+	lgr.Info("GET /orders", pocketlog.Int("status", 200))
+	lgr.Debugf(debugMessage)
+	lgr.Errorf("payment failed")
+
+	expected := "I - GET /orders status=200 region=eu-west-1\n" +
+		"D - " + debugMessage + "\n" +
+		"E - Payment failed region=eu-west-1\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+
+	if errorCount != 1 {
+		t.Errorf("expected 1 error, got %d", errorCount)
+	}
+
+	expectedOrder := "filter filter enrich filter count filter enrich"
+	if got := strings.Join(order, " "); got != expectedOrder {
+		t.Errorf("invalid order, expected %q, got %q", expectedOrder, got)
+	}
+}
+
+func TestLogger_WithHook_panic(t *testing.T) {
+	tw := &testWriter{}
+	var failures []string
+
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithErrorHandler(func(e pocketlog.Entry, err error) {
+			failures = append(failures, e.Message+": "+err.Error())
+		}),
+		pocketlog.WithHook(func(e pocketlog.Entry) (pocketlog.Entry, bool) {
+			e.Message = "rewritten before panicking"
+			panic("broken hook")
+		}),
+		pocketlog.WithHook(func(e pocketlog.Entry) (pocketlog.Entry, bool) {
+			e.Fields = append(e.Fields, pocketlog.Bool("second", true))
+			return e, true
+		}),
+	)
+
+	lgr.Info(infoMessage)
+
+	// The panicking hook is skipped, the following ones still run.
+	expected := "I - " + infoMessage + " second=true\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+
+	expectedFailures := infoMessage + ": hook panicked: broken hook"
+	if got := strings.Join(failures, "\n"); got != expectedFailures {
+		t.Errorf("invalid failures, expected %q, got %q", expectedFailures, got)
+	}
+
+	expectedStats := pocketlog.WriteStats{HookPanics: 1}
+	if got := lgr.WriteStats(); got != expectedStats {
+		t.Errorf("invalid stats, expected %+v, got %+v", expectedStats, got)
+	}
+}
+
+func TestLogger_WithHook_panicWithoutHandler(t *testing.T) {
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(&testWriter{}),
+		pocketlog.WithHook(func(e pocketlog.Entry) (pocketlog.Entry, bool) {
+			panic("broken hook")
+		}),
+	)
+
+	lgr.Info(infoMessage)
+	lgr.With(pocketlog.Int("n", 1)).Info(infoMessage)
+
+	// Panics are counted across child loggers, even without an error handler.
+	expectedStats := pocketlog.WriteStats{HookPanics: 2}
+	if got := lgr.WriteStats(); got != expectedStats {
+		t.Errorf("invalid stats, expected %+v, got %+v", expectedStats, got)
+	}
+}
+
+func TestLogger_WithHook_loggerFields(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithHook(func(e pocketlog.Entry) (pocketlog.Entry, bool) {
+			e.Fields = append(e.Fields, pocketlog.String("hook", e.Message))
+			return e, true
+		}),
+	)
+
+	// The fields of the child have spare capacity.
+	child := lgr.With(pocketlog.Int("a", 1), pocketlog.Int("b", 2), pocketlog.Int("c", 3)).With(pocketlog.Int("d", 4))
+	child.Info("first")
+	child.Info("second")
+
+	expected := "I - first a=1 b=2 c=3 d=4 hook=first\n" +
+		"I - second a=1 b=2 c=3 d=4 hook=second\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
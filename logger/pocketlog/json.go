@@ -0,0 +1,126 @@
+package pocketlog
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s to buf as a quoted JSON string.
+// Control characters are escaped and invalid UTF-8 is replaced by U+FFFD.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			switch {
+			case b == '"' || b == '\\':
+				buf = append(buf, '\\', b)
+			case b == '\n':
+				buf = append(buf, '\\', 'n')
+			case b == '\r':
+				buf = append(buf, '\\', 'r')
+			case b == '\t':
+				buf = append(buf, '\\', 't')
+			case b < 0x20 || b == 0x7f:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xf])
+			default:
+				buf = append(buf, b)
+			}
+			i++
+
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf = append(buf, `\ufffd`...)
+		case r == '\u2028' || r == '\u2029':
+			// Valid JSON, but they break JavaScript parsers reading the line.
+			buf = append(buf, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+		default:
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+
+	return append(buf, '"')
+}
+
+// appendJSONValue appends the JSON encoding of a field value to buf.
+func appendJSONValue(buf []byte, value any) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, "null"...)
+	case string:
+		return appendJSONString(buf, v)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			// JSON has no representation for these.
+			return appendJSONString(buf, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+
+		return strconv.AppendFloat(buf, v, 'g', -1, 64)
+	case error:
+		return appendJSONString(buf, v.Error())
+	case time.Time:
+		return appendJSONString(buf, v.Format(time.RFC3339Nano))
+	case time.Duration:
+		return appendJSONString(buf, v.String())
+	case []Field:
+		buf = append(buf, '{')
+		for i, f := range v {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONMember(buf, f)
+		}
+
+		return append(buf, '}')
+	default:
+		return appendJSONMarshal(buf, v)
+	}
+}
+
+// appendJSONMarshal appends the value using encoding/json, falling back to its
+// text representation if the value cannot be marshalled.
+func appendJSONMarshal(buf []byte, value any) []byte {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return appendJSONString(buf, formatValue(value))
+	}
+
+	return append(buf, encoded...)
+}
+
+// appendJSONFields appends fields to buf as JSON object members, each preceded by a comma.
+func appendJSONFields(buf []byte, fields []Field) []byte {
+	for _, f := range fields {
+		buf = append(buf, ',')
+		buf = appendJSONMember(buf, f)
+	}
+
+	return buf
+}
+
+// appendJSONMember appends a field to buf as a "key":value JSON object member.
+func appendJSONMember(buf []byte, f Field) []byte {
+	buf = appendJSONString(buf, f.Key)
+	buf = append(buf, ':')
+
+	return appendJSONValue(buf, f.Value)
+}
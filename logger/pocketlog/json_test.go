@@ -0,0 +1,101 @@
+package pocketlog_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+func TestLogger_JSON(t *testing.T) {
+	type testCase struct {
+		message     string
+		fields      []pocketlog.Field
+		maxLen      int
+		wantMessage string
+		wantFields  map[string]any
+	}
+
+	tt := map[string]testCase{
+		"plain": {
+			message:     infoMessage,
+			wantMessage: infoMessage,
+		},
+		"control characters": {
+			message:     "tab\there\nnew line \x00 nul \x1b escape \"quoted\" \\",
+			wantMessage: "tab\there\nnew line \x00 nul \x1b escape \"quoted\" \\",
+		},
+		"invalid UTF-8": {
+			message:     "bad \xff byte, good é",
+			wantMessage: "bad � byte, good é",
+		},
+		"fields": {
+			message: infoMessage,
+			fields: []pocketlog.Field{
+				pocketlog.String("user", "gopher\n"),
+				pocketlog.Int("attempt", 3),
+				pocketlog.Float64("ratio", 0.5),
+				pocketlog.Bool("ok", true),
+				pocketlog.Duration("latency", time.Second),
+				pocketlog.Any("tags", []string{"a", "b"}),
+				pocketlog.Any("nothing", nil),
+			},
+			wantMessage: infoMessage,
+			wantFields: map[string]any{
+				"user":    "gopher\n",
+				"attempt": 3.0,
+				"ratio":   0.5,
+				"ok":      true,
+				"latency": "1s",
+				"tags":    []any{"a", "b"},
+				"nothing": nil,
+			},
+		},
+		"truncated": {
+			message:     "This message is \"definitely\" longer than our maxLen.",
+			maxLen:      20,
+			wantMessage: "This message is \"...",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			opts := []pocketlog.Option{pocketlog.WithOutput(tw), pocketlog.WithJSON()}
+			if tc.maxLen > 0 {
+				opts = append(opts, pocketlog.WithMaxLen(tc.maxLen))
+			}
+			lgr := pocketlog.New(pocketlog.LevelDebug, opts...)
+
+			lgr.Info(tc.message, tc.fields...)
+
+			if strings.Count(tw.contents, "\n") != 1 || !strings.HasSuffix(tw.contents, "\n") {
+				t.Fatalf("expected exactly one line, got %q", tw.contents)
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal([]byte(tw.contents), &got); err != nil {
+				t.Fatalf("invalid JSON %q: %s", tw.contents, err)
+			}
+
+			if got["level"] != "info" {
+				t.Errorf("invalid level, expected %q, got %v", "info", got["level"])
+			}
+			if got["msg"] != tc.wantMessage {
+				t.Errorf("invalid message, expected %q, got %q", tc.wantMessage, got["msg"])
+			}
+			if _, err := time.Parse(time.RFC3339Nano, got["time"].(string)); err != nil {
+				t.Errorf("invalid time %v: %s", got["time"], err)
+			}
+			for key, want := range tc.wantFields {
+				wantJSON, _ := json.Marshal(want)
+				gotJSON, _ := json.Marshal(got[key])
+				if string(wantJSON) != string(gotJSON) {
+					t.Errorf("invalid field %q, expected %s, got %s", key, wantJSON, gotJSON)
+				}
+			}
+		})
+	}
+}
@@ -1,30 +1,157 @@
 package pocketlog
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // Level represents an available logging level.
+//
+// Breaking change: adding LevelTrace below LevelDebug and LevelWarn between
+// LevelInfo and LevelError renumbered the levels. LevelDebug went from 0 to 1,
+// LevelInfo from 1 to 2 and LevelError from 2 to 4. The zero value of Level is
+// now LevelTrace. Code that stores levels as numbers, or relies on the zero
+// value meaning debug, must be updated; level names are unaffected.
 type Level byte
 
 const (
-	// LevelDebug represents the lowest level of log, mostly used
-	// for debugging purposes.
-	LevelDebug Level = iota
+	// LevelTrace represents the lowest level of log, used to follow the
+	// execution of the code step by step.
+	LevelTrace Level = iota
+	// LevelDebug represents a logging level mostly used for debugging purposes.
+	LevelDebug
 	// LevelInfo represents a logging level that contains information
 	// deemed valuable.
 	LevelInfo
-	// LevelError represents the highest logging level, only to be used to
-	// to trace errors.
+	// LevelWarn represents a logging level for unexpected events that the
+	// program can recover from.
+	LevelWarn
+	// LevelError represents a logging level only to be used to trace errors.
 	LevelError
+	// LevelPanic represents a logging level for errors after which the
+	// logger panics.
+	LevelPanic
+	// LevelFatal represents the highest logging level, for errors after
+	// which the program exits.
+	LevelFatal
 )
 
+// String returns the single-letter representation of the level.
 func (l Level) String() string {
 	switch l {
+	case LevelTrace:
+		return "T"
 	case LevelDebug:
 		return "D"
 	case LevelInfo:
 		return "I"
+	case LevelWarn:
+		return "W"
 	case LevelError:
 		return "E"
+	case LevelPanic:
+		return "P"
+	case LevelFatal:
+		return "F"
 	default:
 		// Should never happen
 		return ""
 	}
 }
+
+// Name returns the lowercase name of the level, such as "info".
+func (l Level) Name() string {
+	switch l {
+	case LevelTrace:
+		return "trace"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelPanic:
+		return "panic"
+	case LevelFatal:
+		return "fatal"
+	default:
+		return ""
+	}
+}
+
+// ErrUnknownLevel is returned when a text doesn't describe a level.
+var ErrUnknownLevel = errors.New("unknown level")
+
+// maxLevel is the highest defined level.
+const maxLevel = LevelFatal
+
+// ParseLevel returns the level described by s, ignoring case and surrounding
+// spaces. It accepts level names ("info", and "warning" for LevelWarn),
+// letters ("I") and numeric values ("2").
+func ParseLevel(s string) (Level, error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+	if text == "warning" {
+		return LevelWarn, nil
+	}
+
+	for l := LevelTrace; l <= maxLevel; l++ {
+		if text == l.Name() || text == strings.ToLower(l.String()) {
+			return l, nil
+		}
+	}
+
+	if n, err := strconv.ParseUint(text, 10, 8); err == nil && Level(n) <= maxLevel {
+		return Level(n), nil
+	}
+
+	return 0, fmt.Errorf("%w %q", ErrUnknownLevel, s)
+}
+
+// MarshalText implements encoding.TextMarshaler, using the level's name.
+func (l Level) MarshalText() ([]byte, error) {
+	if l > maxLevel {
+		return nil, fmt.Errorf("%w %d", ErrUnknownLevel, l)
+	}
+
+	return []byte(l.Name()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting anything ParseLevel does.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = level
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting a string that ParseLevel
+// accepts, or a number such as 2.
+func (l *Level) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	if len(data) == 0 || data[0] != '"' {
+		return l.UnmarshalText(data)
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+
+	return l.UnmarshalText([]byte(text))
+}
+
+// Set implements flag.Value, so that a level can be bound to a command-line flag with flag.Var.
+func (l *Level) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,110 @@
+package pocketlog_test
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+func TestParseLevel(t *testing.T) {
+	type testCase struct {
+		input   string
+		want    pocketlog.Level
+		wantErr bool
+	}
+
+	tt := map[string]testCase{
+		"name":            {input: "debug", want: pocketlog.LevelDebug},
+		"upper case name": {input: "ERROR", want: pocketlog.LevelError},
+		"mixed case name": {input: "Warn", want: pocketlog.LevelWarn},
+		"alias":           {input: "warning", want: pocketlog.LevelWarn},
+		"letter":          {input: "t", want: pocketlog.LevelTrace},
+		"upper letter":    {input: "F", want: pocketlog.LevelFatal},
+		"number":          {input: "2", want: pocketlog.LevelInfo},
+		"spaces":          {input: " info\n", want: pocketlog.LevelInfo},
+		"unknown name":    {input: "verbose", wantErr: true},
+		"out of range":    {input: "7", wantErr: true},
+		"negative":        {input: "-1", wantErr: true},
+		"empty":           {input: "", wantErr: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := pocketlog.ParseLevel(tc.input)
+			if tc.wantErr {
+				if !errors.Is(err, pocketlog.ErrUnknownLevel) {
+					t.Errorf("expected ErrUnknownLevel, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tc.want {
+				t.Errorf("invalid level, expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLevel_JSON(t *testing.T) {
+	type config struct {
+		Level pocketlog.Level `json:"level"`
+	}
+
+	var cfg config
+	if err := json.Unmarshal([]byte(`{"level":"warn"}`), &cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cfg.Level != pocketlog.LevelWarn {
+		t.Errorf("invalid level, expected %v, got %v", pocketlog.LevelWarn, cfg.Level)
+	}
+
+	encoded, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := `{"level":"warn"}`; string(encoded) != expected {
+		t.Errorf("invalid JSON, expected %s, got %s", expected, encoded)
+	}
+
+	if err := json.Unmarshal([]byte(`{"level":1}`), &cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cfg.Level != pocketlog.LevelDebug {
+		t.Errorf("invalid level, expected %v, got %v", pocketlog.LevelDebug, cfg.Level)
+	}
+
+	for _, invalid := range []string{`{"level":"loud"}`, `{"level":42}`, `{"level":true}`} {
+		if err := json.Unmarshal([]byte(invalid), &cfg); !errors.Is(err, pocketlog.ErrUnknownLevel) {
+			t.Errorf("expected ErrUnknownLevel for %s, got %v", invalid, err)
+		}
+	}
+
+	if _, err := json.Marshal(config{Level: pocketlog.Level(42)}); !errors.Is(err, pocketlog.ErrUnknownLevel) {
+		t.Errorf("expected ErrUnknownLevel, got %v", err)
+	}
+}
+
+func TestLevel_Flag(t *testing.T) {
+	level := pocketlog.LevelInfo
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&level, "level", "logging threshold")
+
+	if err := fs.Parse([]string{"-level", "error"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if level != pocketlog.LevelError {
+		t.Errorf("invalid level, expected %v, got %v", pocketlog.LevelError, level)
+	}
+
+	fs.SetOutput(&testWriter{})
+	if err := fs.Parse([]string{"-level", "nope"}); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
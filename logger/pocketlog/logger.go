@@ -1,75 +1,538 @@
 package pocketlog
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"time"
 )
 
 // Logger is used to log information.
+// It is safe for concurrent use. Each entry is written with a single call to
+// the output's Write method, and writes are serialized across the logger and
+// its children.
+// A logger writes to its sinks, by default a single one formatting entries to
+// the output.
 type Logger struct {
-	threshold Level
+	// level is shared with child loggers.
+	level *AtomicLevel
+	// levels, if set, holds thresholds overriding level for named loggers.
+	levels *LevelRegistry
+	name   string
+	maxLen int
+	fields []Field
+
+	// output and formatter make the default sink, unless sinks are set by an option.
 	output    io.Writer
-	maxLen    int
+	formatter Formatter
+	// color, if set, decides whether text output is colorized.
+	color *ColorMode
+	// sinks are shared with child loggers.
+	sinks []Sink
+
+	clock      Clock
+	timeLayout string
+	// location is nil to keep the clock's time zone.
+	location *time.Location
+
+	addCaller  bool
+	callerSkip int
+
+	// exit is called by Fatal and Fatalf.
+	exit func(code int)
+
+	samplingOpts samplingOptions
+	// sampler is nil unless sampling or rate limiting is enabled. It is shared with child loggers.
+	sampler *sampler
+
+	redactOpts redactionOptions
+	// redaction is nil unless sensitive keys or patterns are set.
+	redaction *redaction
+
+	// diag handles write failures. It is shared with child loggers.
+	diag *diagnostics
+
+	// hooks process entries before they are sampled and written.
+	hooks []registeredHook
+
+	// extractors pull fields out of the context of the *Context methods.
+	extractors []ContextExtractor
+
+	dedupWindow time.Duration
+	// dedup is nil unless repeated entries are collapsed. It is shared with child loggers.
+	dedup *deduper
+
+	asyncOpts asyncOptions
+	// async is nil unless the logger is asynchronous. It is shared with child loggers.
+	async *asyncQueue
+}
+
+// asyncOptions holds the settings of an asynchronous logger until it is created.
+type asyncOptions struct {
+	enabled   bool
+	size      int
+	policy    OverflowPolicy
+	dropLevel Level
 }
 
 // New returns you a logger, ready to log at the required threshold.
 // If a log line's length exceeds maxLen, it will be truncated.
 // The default output is os.Stdout.
 // The default maximum log line length is 1000 runes.
+// The default formatter is a TextFormatter.
+// Entries are timestamped by the system clock, with the TimeLayoutRFC3339Nano layout.
 func New(threshold Level, opts ...Option) *Logger {
-	lgr := &Logger{threshold: threshold, output: os.Stdout, maxLen: 1000}
+	lgr := &Logger{
+		diag:       &diagnostics{},
+		level:      NewAtomicLevel(threshold),
+		output:     os.Stdout,
+		maxLen:     1000,
+		formatter:  TextFormatter{},
+		clock:      systemClock{},
+		timeLayout: TimeLayoutRFC3339Nano,
+		exit:       os.Exit,
+		asyncOpts:  asyncOptions{dropLevel: LevelError},
+	}
 
 	for _, opt := range opts {
 		opt(lgr)
 	}
 
+	if lgr.color != nil {
+		lgr.formatter = colorFormatter(lgr.formatter, colorEnabled(*lgr.color, lgr.output))
+	}
+
+	lgr.diag.formatter = lgr.formatter
+
+	if len(lgr.sinks) == 0 {
+		lgr.sinks = []Sink{NewSink(lgr.output, SinkFormatter(lgr.formatter))}
+	}
+
+	for _, s := range lgr.sinks {
+		if r, ok := s.(failureReporter); ok {
+			r.reportFailures(lgr.diag)
+		}
+	}
+
+	lgr.redaction = newRedaction(lgr.redactOpts)
+	lgr.sampler = newSampler(lgr.samplingOpts, lgr.clock, lgr.emitInternal)
+	lgr.dedup = newDeduper(lgr.dedupWindow, lgr.clock, lgr.emitInternal)
+
+	if lgr.asyncOpts.enabled {
+		lgr.async = newAsyncQueue(lgr.asyncOpts.size, lgr.asyncOpts.policy, lgr.asyncOpts.dropLevel, lgr.write)
+	}
+
 	return lgr
 }
 
+// Tracef formats and prints a message if the log level is trace or higher.
+func (l *Logger) Tracef(format string, args ...any) {
+	if !l.enabled(LevelTrace) {
+		return
+	}
+
+	l.log(LevelTrace, format, fmt.Sprintf(format, redactArgs(args)...), nil)
+}
+
 // Debugf formats and prints a message if the log level is debug or higher.
 func (l *Logger) Debugf(format string, args ...any) {
-	if LevelDebug < l.threshold {
+	if !l.enabled(LevelDebug) {
 		return
 	}
 
-	l.logf(LevelDebug, format, args...)
+	l.log(LevelDebug, format, fmt.Sprintf(format, redactArgs(args)...), nil)
 }
 
 // Infof formats and prints a message if the log level is info or higher.
 func (l *Logger) Infof(format string, args ...any) {
-	if LevelInfo < l.threshold {
+	if !l.enabled(LevelInfo) {
+		return
+	}
+
+	l.log(LevelInfo, format, fmt.Sprintf(format, redactArgs(args)...), nil)
+}
+
+// Warnf formats and prints a message if the log level is warn or higher.
+func (l *Logger) Warnf(format string, args ...any) {
+	if !l.enabled(LevelWarn) {
 		return
 	}
 
-	l.logf(LevelInfo, format, args...)
+	l.log(LevelWarn, format, fmt.Sprintf(format, redactArgs(args)...), nil)
 }
 
 // Errorf formats and prints a message if the log level is error or higher.
 func (l *Logger) Errorf(format string, args ...any) {
-	if LevelError < l.threshold {
+	if !l.enabled(LevelError) {
+		return
+	}
+
+	l.log(LevelError, format, fmt.Sprintf(format, redactArgs(args)...), nil)
+}
+
+// Panicf formats and prints a message if the log level is panic or higher, and
+// flushes the logger, then panics with the redacted message, whatever the level.
+func (l *Logger) Panicf(format string, args ...any) {
+	message := fmt.Sprintf(format, redactArgs(args)...)
+	if l.enabled(LevelPanic) {
+		l.log(LevelPanic, format, message, nil)
+		l.flushBeforePanic()
+	}
+
+	panic(l.redaction.redactString(message))
+}
+
+// Fatalf formats and prints a message, flushes the logger, and exits the program with status 1.
+func (l *Logger) Fatalf(format string, args ...any) {
+	if l.enabled(LevelFatal) {
+		l.log(LevelFatal, format, fmt.Sprintf(format, redactArgs(args)...), nil)
+	}
+
+	l.exitAfterFlush()
+}
+
+// Trace prints a message with its fields if the log level is trace or higher.
+func (l *Logger) Trace(message string, fields ...Field) {
+	if !l.enabled(LevelTrace) {
+		return
+	}
+
+	l.log(LevelTrace, message, message, fields)
+}
+
+// Debug prints a message with its fields if the log level is debug or higher.
+func (l *Logger) Debug(message string, fields ...Field) {
+	if !l.enabled(LevelDebug) {
+		return
+	}
+
+	l.log(LevelDebug, message, message, fields)
+}
+
+// Info prints a message with its fields if the log level is info or higher.
+func (l *Logger) Info(message string, fields ...Field) {
+	if !l.enabled(LevelInfo) {
+		return
+	}
+
+	l.log(LevelInfo, message, message, fields)
+}
+
+// Warn prints a message with its fields if the log level is warn or higher.
+func (l *Logger) Warn(message string, fields ...Field) {
+	if !l.enabled(LevelWarn) {
+		return
+	}
+
+	l.log(LevelWarn, message, message, fields)
+}
+
+// Error prints a message with its fields if the log level is error or higher.
+func (l *Logger) Error(message string, fields ...Field) {
+	if !l.enabled(LevelError) {
+		return
+	}
+
+	l.log(LevelError, message, message, fields)
+}
+
+// Panic prints a message with its fields if the log level is panic or higher, and
+// flushes the logger, then panics with the redacted message, whatever the level.
+func (l *Logger) Panic(message string, fields ...Field) {
+	if l.enabled(LevelPanic) {
+		l.log(LevelPanic, message, message, fields)
+		l.flushBeforePanic()
+	}
+
+	panic(l.redaction.redactString(message))
+}
+
+// Fatal prints a message with its fields, flushes the logger, and exits the program with status 1.
+func (l *Logger) Fatal(message string, fields ...Field) {
+	if l.enabled(LevelFatal) {
+		l.log(LevelFatal, message, message, fields)
+	}
+
+	l.exitAfterFlush()
+}
+
+// Level returns the current threshold of the logger.
+func (l *Logger) Level() Level {
+	return l.level.Level()
+}
+
+// SetLevel changes the threshold of the logger, its parent and its children.
+// It is safe to call while the loggers are in use.
+func (l *Logger) SetLevel(level Level) {
+	l.level.SetLevel(level)
+}
+
+// enabled tells whether entries of the given level pass the threshold: that of
+// the logger's name in the level registry, or the logger's own.
+func (l *Logger) enabled(level Level) bool {
+	if l.levels != nil {
+		if threshold, ok := l.levels.Lookup(l.name); ok {
+			return level >= threshold
+		}
+	}
+
+	return l.level.Enabled(level)
+}
+
+// Named returns a child logger whose name is the parent's followed by a dot and
+// the given name, such as "db.pool". The name is printed on every entry, and
+// selects the threshold of the logger in the level registry.
+func (l *Logger) Named(name string) *Logger {
+	if name == "" {
+		return l
+	}
+
+	child := *l
+	if l.name == "" {
+		child.name = name
+	} else {
+		child.name = l.name + "." + name
+	}
+
+	return &child
+}
+
+// With returns a child logger that adds the given fields to every line it prints.
+// The parent logger is left untouched.
+func (l *Logger) With(fields ...Field) *Logger {
+	child := *l
+	child.fields = append(l.fields[:len(l.fields):len(l.fields)], fields...)
+
+	return &child
+}
+
+// Flush writes the pending repetition counts and sampling summaries, then waits until the entries
+// logged so far have been written, by the logger and by its sinks, or until the
+// context is done. It returns immediately when nothing is asynchronous.
+func (l *Logger) Flush(ctx context.Context) error {
+	if l.dedup != nil {
+		l.dedup.flush()
+	}
+
+	if l.sampler != nil {
+		l.emitInternal(l.sampler.drain())
+	}
+
+	if l.async != nil {
+		if err := l.async.flush(ctx); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, s := range l.sinks {
+		if f, ok := s.(flusher); ok {
+			errs = append(errs, f.Flush(ctx))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close writes the pending entries and stops the background goroutines of an
+// asynchronous logger and of its sinks, giving up when the context is done.
+// Entries logged after Close are written synchronously.
+// Close is shared by a logger and its children: closing one closes them all.
+func (l *Logger) Close(ctx context.Context) error {
+	if l.dedup != nil {
+		l.dedup.flush()
+	}
+
+	if l.sampler != nil {
+		l.emitInternal(l.sampler.drain())
+	}
+
+	if l.async != nil {
+		if err := l.async.close(ctx); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, s := range l.sinks {
+		if c, ok := s.(closer); ok {
+			errs = append(errs, c.Close(ctx))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// fatalFlushTimeout bounds the time Panic, Fatal and their printf-style
+// variants wait for queued entries before panicking or exiting.
+const fatalFlushTimeout = 5 * time.Second
+
+// exitAfterFlush writes the pending entries and exits the program with status 1.
+func (l *Logger) exitAfterFlush() {
+	ctx, cancel := context.WithTimeout(context.Background(), fatalFlushTimeout)
+	defer cancel()
+
+	_ = l.Close(ctx)
+	l.exit(1)
+}
+
+// flushBeforePanic writes the pending entries, so that the panic entry of an
+// asynchronous logger isn't lost if the panic is not recovered. Unlike before
+// exiting, the logger is not closed, since the panic may be recovered.
+func (l *Logger) flushBeforePanic() {
+	ctx, cancel := context.WithTimeout(context.Background(), fatalFlushTimeout)
+	defer cancel()
+
+	_ = l.Flush(ctx)
+}
+
+// Dropped returns the number of entries an asynchronous logger dropped because its buffer was full.
+func (l *Logger) Dropped() uint64 {
+	if l.async == nil {
+		return 0
+	}
+
+	return l.async.dropped.Load()
+}
+
+// WriteStats returns the counters of the entries the logger failed to write,
+// and of the panics of its hooks.
+func (l *Logger) WriteStats() WriteStats {
+	return l.diag.stats()
+}
+
+// AddCallerSkip returns a child logger that skips n more stack frames when
+// recording callers. Libraries wrapping the logger use it so that entries
+// report their users' call sites rather than the wrapper's.
+func (l *Logger) AddCallerSkip(n int) *Logger {
+	child := *l
+	child.callerSkip += n
+
+	return &child
+}
+
+// callerDepth is the number of frames between log and the user's call site.
+// Every exported logging method must call log directly to keep it accurate.
+const callerDepth = 2
+
+// log builds an entry out of the message and the fields, the logger's first,
+// and prints it to the output. The template is the format of printf-style
+// messages, and the message itself otherwise.
+func (l *Logger) log(level Level, template, message string, fields []Field) {
+	entry := l.newEntry(level, l.now(), message, fields)
+	entry.template = l.redaction.redactString(template)
+
+	if l.addCaller {
+		entry.Caller = callerAt(callerDepth + l.callerSkip)
+	}
+
+	l.dispatch(entry)
+}
+
+// newEntry returns an entry holding the logger's settings and fields, followed by
+// the given ones, with sensitive data redacted.
+func (l *Logger) newEntry(level Level, t time.Time, message string, fields []Field) Entry {
+	message = l.redaction.redactString(message)
+
+	return Entry{
+		Level:      level,
+		LoggerName: l.name,
+		Time:       t,
+		Message:    message,
+		Fields:     l.redaction.redactFields(l.entryFields(fields)),
+		template:   message,
+		maxLen:     l.maxLen,
+		timeLayout: l.timeLayout,
+	}
+}
+
+// dispatch runs the hooks, samples the entry and collapses repetitions, then emits it.
+func (l *Logger) dispatch(entry Entry) {
+	if len(l.hooks) > 0 {
+		var keep bool
+		if entry, keep = l.runHooks(entry); !keep {
+			return
+		}
+
+		// Hooks may add sensitive data to the entry.
+		entry = l.redaction.redactEntry(entry)
+	}
+
+	if l.sampler != nil {
+		keep, summaries := l.sampler.sample(entry)
+		l.emitInternal(summaries)
+		if !keep {
+			return
+		}
+	}
+
+	if l.dedup != nil && !l.dedup.dedup(entry) {
+		return
+	}
+
+	l.emit(entry)
+}
+
+// emit queues the entry if the logger is asynchronous, and writes it otherwise.
+func (l *Logger) emit(entry Entry) {
+	if l.async != nil && l.async.enqueue(entry) {
 		return
 	}
 
-	l.logf(LevelError, format, args...)
+	l.write(entry)
+}
+
+// emitInternal timestamps and emits entries produced by the logger itself,
+// such as sampling summaries.
+func (l *Logger) emitInternal(entries []Entry) {
+	for _, e := range entries {
+		e.Time = l.now()
+		e.maxLen = l.maxLen
+		e.timeLayout = l.timeLayout
+		l.emit(e)
+	}
+}
+
+// write hands the entry over to the sinks, and handles their failures.
+func (l *Logger) write(entry Entry) {
+	if err := writeSinks(l.sinks, entry); err != nil {
+		l.diag.writeFailed(entry, err)
+	}
 }
 
-// logf prints the message to the output.
-func (l *Logger) logf(level Level, format string, args ...any) {
-	message := fmt.Sprintf(format, args...)
-	message = fmt.Sprintf("%s - %s", level, message)
+// entryFields returns the logger's fields followed by the given ones.
+func (l *Logger) entryFields(fields []Field) []Field {
+	switch {
+	case len(l.fields) == 0:
+		return fields
+	case len(fields) == 0:
+		return l.fields
+	default:
+		return append(l.fields[:len(l.fields):len(l.fields)], fields...)
+	}
+}
 
-	message = l.truncate(message)
-	message = fmt.Sprintf("%s\n", message)
+// now returns the time of the clock in the configured time zone,
+// or the zero time if timestamps are disabled.
+func (l *Logger) now() time.Time {
+	if l.timeLayout == "" {
+		return time.Time{}
+	}
 
-	_, _ = fmt.Fprintf(l.output, "%s", message)
+	return l.stamp(l.clock.Now())
 }
 
-func (l *Logger) truncate(message string) string {
-	runes := []rune(message)
-	if len(runes) <= l.maxLen-3 {
-		return message
+// stamp returns t in the configured time zone, or the zero time if timestamps are disabled.
+func (l *Logger) stamp(t time.Time) time.Time {
+	if l.timeLayout == "" || t.IsZero() {
+		return time.Time{}
+	}
+
+	if l.location != nil {
+		t = t.In(l.location)
 	}
 
-	return string(runes[:l.maxLen-3]) + "..."
+	return t
 }
@@ -1,7 +1,9 @@
 package pocketlog_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
 )
@@ -12,6 +14,14 @@ const (
 	errorMessage = "This is an error message"
 )
 
+// timestamp is how the text formatter prints fixedClock's time.
+const timestamp = "2025-01-02T03:04:05.000000006Z "
+
+// fixedClock always tells the same time.
+var fixedClock = pocketlog.ClockFunc(func() time.Time {
+	return time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+})
+
 type testWriter struct {
 	contents string
 }
@@ -30,22 +40,22 @@ func TestLogger_DebugfInfofErrorf(t *testing.T) {
 	tt := map[string]testCase{
 		"debug": {
 			level:    pocketlog.LevelDebug,
-			expected: "D - " + debugMessage + "\n" + "I - " + infoMessage + "\n" + "E - " + errorMessage + "\n",
+			expected: timestamp + "D - " + debugMessage + "\n" + timestamp + "I - " + infoMessage + "\n" + timestamp + "E - " + errorMessage + "\n",
 		},
 		"info": {
 			level:    pocketlog.LevelInfo,
-			expected: "I - " + infoMessage + "\n" + "E - " + errorMessage + "\n",
+			expected: timestamp + "I - " + infoMessage + "\n" + timestamp + "E - " + errorMessage + "\n",
 		},
 		"error": {
 			level:    pocketlog.LevelError,
-			expected: "E - " + errorMessage + "\n",
+			expected: timestamp + "E - " + errorMessage + "\n",
 		},
 	}
 
 	for name, tc := range tt {
 		t.Run(name, func(t *testing.T) {
 			tw := &testWriter{}
-			lgr := pocketlog.New(tc.level, pocketlog.WithOutput(tw))
+			lgr := pocketlog.New(tc.level, pocketlog.WithOutput(tw), pocketlog.WithClock(fixedClock))
 
 			lgr.Debugf(debugMessage)
 			lgr.Infof(infoMessage)
@@ -61,14 +71,252 @@ func TestLogger_DebugfInfofErrorf(t *testing.T) {
 func TestLogger_Truncation(t *testing.T) {
 	tw := &testWriter{}
 	maxLen := 10
-	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithMaxLen(maxLen))
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithMaxLen(maxLen), pocketlog.WithClock(fixedClock))
 
 	longMessage := "This message is definitely longer than out maxLen."
 	lgr.Infof("%s", longMessage)
 
-	expected := "I - Thi...\n"
+	expected := timestamp + "I - Thi...\n"
 
 	if tw.contents != expected {
 		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
 	}
 }
+
+func TestLogger_Fields(t *testing.T) {
+	type testCase struct {
+		fields   []pocketlog.Field
+		expected string
+	}
+
+	tt := map[string]testCase{
+		"no fields": {
+			expected: "I - " + infoMessage + "\n",
+		},
+		"typed fields": {
+			fields: []pocketlog.Field{
+				pocketlog.String("user", "gopher"),
+				pocketlog.Int("attempt", 3),
+				pocketlog.Bool("ok", true),
+				pocketlog.Duration("latency", 1500*time.Millisecond),
+				pocketlog.Err(errors.New("boom")),
+			},
+			expected: "I - " + infoMessage + " user=gopher attempt=3 ok=true latency=1.5s error=boom\n",
+		},
+		"quoted values": {
+			fields: []pocketlog.Field{
+				pocketlog.String("query", "a=b"),
+				pocketlog.String("name", "Jane Doe"),
+				pocketlog.String("empty", ""),
+				pocketlog.String("quote", `say "hi"`),
+			},
+			expected: "I - " + infoMessage + ` query="a=b" name="Jane Doe" empty="" quote="say \"hi\""` + "\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""))
+
+			lgr.Info(infoMessage, tc.fields...)
+
+			if tw.contents != tc.expected {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+func TestLogger_With(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""))
+
+	child := lgr.With(pocketlog.String("request_id", "42"))
+	grandChild := child.With(pocketlog.String("user", "gopher"))
+
+	lgr.Infof(infoMessage)
+	child.Errorf(errorMessage)
+	grandChild.Debug(debugMessage, pocketlog.Int("n", 1))
+	child.Info(infoMessage)
+
+	expected := "I - " + infoMessage + "\n" +
+		"E - " + errorMessage + " request_id=42\n" +
+		"D - " + debugMessage + " request_id=42 user=gopher n=1\n" +
+		"I - " + infoMessage + " request_id=42\n"
+
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_Timestamps(t *testing.T) {
+	type testCase struct {
+		opts     []pocketlog.Option
+		expected string
+	}
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %s", err)
+	}
+
+	tt := map[string]testCase{
+		"default layout": {
+			expected: timestamp + "I - " + infoMessage + "\n",
+		},
+		"unix millis": {
+			opts:     []pocketlog.Option{pocketlog.WithTimeLayout(pocketlog.TimeLayoutUnixMilli)},
+			expected: "1735787045000 I - " + infoMessage + "\n",
+		},
+		"custom layout": {
+			opts:     []pocketlog.Option{pocketlog.WithTimeLayout(time.Kitchen)},
+			expected: "3:04AM I - " + infoMessage + "\n",
+		},
+		"disabled": {
+			opts:     []pocketlog.Option{pocketlog.WithTimeLayout("")},
+			expected: "I - " + infoMessage + "\n",
+		},
+		"UTC": {
+			opts: []pocketlog.Option{
+				pocketlog.WithClock(pocketlog.ClockFunc(func() time.Time {
+					return time.Date(2025, 1, 2, 4, 4, 5, 0, paris)
+				})),
+				pocketlog.WithUTC(),
+			},
+			expected: "2025-01-02T03:04:05Z I - " + infoMessage + "\n",
+		},
+		"JSON unix millis": {
+			opts:     []pocketlog.Option{pocketlog.WithJSON(), pocketlog.WithTimeLayout(pocketlog.TimeLayoutUnixMilli)},
+			expected: `{"time":1735787045000,"level":"info","msg":"` + infoMessage + `"}` + "\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			opts := append([]pocketlog.Option{pocketlog.WithOutput(tw), pocketlog.WithClock(fixedClock)}, tc.opts...)
+			lgr := pocketlog.New(pocketlog.LevelDebug, opts...)
+
+			lgr.Infof(infoMessage)
+
+			if tw.contents != tc.expected {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+func TestLogger_Levels(t *testing.T) {
+	tw := &testWriter{}
+	exitCode := -1
+	lgr := pocketlog.New(pocketlog.LevelTrace,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithExitFunc(func(code int) { exitCode = code }),
+	)
+
+	lgr.Tracef("trace %d", 1)
+	lgr.Trace("trace", pocketlog.Int("n", 2))
+	lgr.Warnf("warn %d", 1)
+	lgr.Warn("warn", pocketlog.Int("n", 2))
+	lgr.Fatalf("fatal %d", 1)
+
+	expected := "T - trace 1\nT - trace n=2\nW - warn 1\nW - warn n=2\nF - fatal 1\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+	if exitCode != 1 {
+		t.Errorf("expected exit code 1, got %d", exitCode)
+	}
+}
+
+func TestLogger_Thresholds(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelWarn, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""))
+
+	lgr.Tracef(debugMessage)
+	lgr.Debugf(debugMessage)
+	lgr.Infof(infoMessage)
+	lgr.Warnf(infoMessage)
+	lgr.Errorf(errorMessage)
+
+	expected := "W - " + infoMessage + "\nE - " + errorMessage + "\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_Fatal(t *testing.T) {
+	tw := &testWriter{}
+	exitCode := -1
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithAsync(10, pocketlog.OverflowBlock),
+		pocketlog.WithExitFunc(func(code int) { exitCode = code }),
+	)
+
+	lgr.Infof(infoMessage)
+	lgr.Fatal("cannot continue", pocketlog.String("reason", "disk"))
+
+	// The queue is flushed before exiting.
+	expected := "I - " + infoMessage + "\nF - cannot continue reason=disk\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+	if exitCode != 1 {
+		t.Errorf("expected exit code 1, got %d", exitCode)
+	}
+}
+
+func TestLogger_Panic(t *testing.T) {
+	type testCase struct {
+		log func(lgr *pocketlog.Logger)
+	}
+
+	tt := map[string]testCase{
+		"Panicf": {log: func(lgr *pocketlog.Logger) { lgr.Panicf("out of %s", "range") }},
+		"Panic":  {log: func(lgr *pocketlog.Logger) { lgr.Panic("out of range") }},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""))
+
+			defer func() {
+				if r := recover(); r != "out of range" {
+					t.Errorf("expected a panic with the message, got %v", r)
+				}
+
+				if expected := "P - out of range\n"; tw.contents != expected {
+					t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+				}
+			}()
+
+			tc.log(lgr)
+		})
+	}
+}
+
+func TestLevel_StringName(t *testing.T) {
+	levels := map[pocketlog.Level][2]string{
+		pocketlog.LevelTrace: {"T", "trace"},
+		pocketlog.LevelDebug: {"D", "debug"},
+		pocketlog.LevelInfo:  {"I", "info"},
+		pocketlog.LevelWarn:  {"W", "warn"},
+		pocketlog.LevelError: {"E", "error"},
+		pocketlog.LevelPanic: {"P", "panic"},
+		pocketlog.LevelFatal: {"F", "fatal"},
+	}
+
+	for level, want := range levels {
+		if got := level.String(); got != want[0] {
+			t.Errorf("invalid String, expected %q, got %q", want[0], got)
+		}
+		if got := level.Name(); got != want[1] {
+			t.Errorf("invalid Name, expected %q, got %q", want[1], got)
+		}
+	}
+}
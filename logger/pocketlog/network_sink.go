@@ -0,0 +1,399 @@
+package pocketlog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// netDialTimeout bounds the time spent connecting to the peer of a NetworkSink.
+const netDialTimeout = 5 * time.Second
+
+// netMinBackoff is the shortest delay between connection attempts, so that a
+// sink whose peer is down does not spin.
+const netMinBackoff = time.Millisecond
+
+// NetworkSink is a Sink sending formatted entries to a peer over TCP, UDP or a
+// unix socket, such as a local collector agent. Entries are queued in a bounded
+// buffer and sent by a background goroutine, which connects lazily and, when
+// the peer goes away, reconnects with an exponential backoff and jitter. While
+// the peer is down, entries are kept in the buffer, the oldest being dropped
+// when it is full. Entries written to a connection just before the peer closed
+// it may be lost.
+type NetworkSink struct {
+	network    string
+	address    string
+	formatter  Formatter
+	level      Level
+	bufferSize int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	// mu guards queue and nextSeq, and prevents entries from being queued once the sink is closed.
+	mu      sync.Mutex
+	queue   []netMessage
+	nextSeq uint64
+
+	// wake signals the goroutine sending entries that one was queued.
+	wake   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	sent       atomic.Uint64
+	dropped    atomic.Uint64
+	reconnects atomic.Uint64
+	connected  atomic.Bool
+	// everConnected is only accessed by the goroutine sending entries.
+	everConnected bool
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// netMessage is a formatted entry waiting to be sent.
+type netMessage struct {
+	seq  uint64
+	data []byte
+}
+
+// NetworkStats holds the counters of a NetworkSink.
+type NetworkStats struct {
+	// Sent is the number of entries written to a connection.
+	Sent uint64
+	// Dropped is the number of entries discarded because the buffer was full,
+	// or because they were still pending when the sink was closed.
+	Dropped uint64
+	// Reconnects is the number of connections made after the first one.
+	Reconnects uint64
+	// Buffered is the number of entries waiting to be sent.
+	Buffered int
+	// Connected tells whether the sink holds a connection to the peer.
+	Connected bool
+}
+
+// NetOption defines a functional option to a NetworkSink.
+type NetOption func(*NetworkSink)
+
+// NetFormatter returns a configuration function that sets the formatter of a
+// network sink. It defaults to a TextFormatter.
+func NetFormatter(formatter Formatter) NetOption {
+	return func(s *NetworkSink) {
+		s.formatter = formatter
+	}
+}
+
+// NetLevel returns a configuration function that sets the minimum level of the
+// entries a network sink sends. The logger's threshold still applies first.
+func NetLevel(level Level) NetOption {
+	return func(s *NetworkSink) {
+		s.level = level
+	}
+}
+
+// NetBufferSize returns a configuration function that sets the number of
+// entries a network sink buffers. It defaults to 1000.
+func NetBufferSize(size int) NetOption {
+	return func(s *NetworkSink) {
+		s.bufferSize = size
+	}
+}
+
+// NetBackoff returns a configuration function that sets the delays between
+// connection attempts: the first one waits about minDelay, and each failure
+// doubles the delay, up to maxDelay. They default to 100ms and 30s. Delays
+// shorter than a millisecond are raised to a millisecond.
+func NetBackoff(minDelay, maxDelay time.Duration) NetOption {
+	return func(s *NetworkSink) {
+		s.minBackoff = minDelay
+		s.maxBackoff = maxDelay
+	}
+}
+
+// NewNetworkSink returns a sink sending entries to the address on the network,
+// which is one of "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix" and
+// "unixgram". It does not wait for the peer to be reachable.
+func NewNetworkSink(network, address string, opts ...NetOption) (*NetworkSink, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("unsupported network %q", network)
+	}
+
+	s := &NetworkSink{
+		network:    network,
+		address:    address,
+		formatter:  TextFormatter{},
+		level:      LevelTrace,
+		bufferSize: 1000,
+		minBackoff: 100 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.bufferSize = max(s.bufferSize, 1)
+	s.minBackoff = max(s.minBackoff, netMinBackoff)
+	s.maxBackoff = max(s.maxBackoff, s.minBackoff)
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	go s.run()
+
+	return s, nil
+}
+
+// WriteEntry implements Sink. It formats the entry and queues it.
+func (s *NetworkSink) WriteEntry(e Entry) error {
+	if e.Level < s.level {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := s.formatter.Format(&buf, e); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return net.ErrClosed
+	}
+
+	if len(s.queue) >= s.bufferSize {
+		s.queue = s.queue[1:]
+		s.dropped.Add(1)
+	}
+	s.queue = append(s.queue, netMessage{seq: s.nextSeq, data: buf.Bytes()})
+	s.nextSeq++
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Stats returns the counters of the sink.
+func (s *NetworkSink) Stats() NetworkStats {
+	s.mu.Lock()
+	buffered := len(s.queue)
+	s.mu.Unlock()
+
+	return NetworkStats{
+		Sent:       s.sent.Load(),
+		Dropped:    s.dropped.Load(),
+		Reconnects: s.reconnects.Load(),
+		Buffered:   buffered,
+		Connected:  s.connected.Load(),
+	}
+}
+
+// Dropped returns the number of entries the sink discarded.
+func (s *NetworkSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Flush waits until the buffered entries have been sent, or the context is done.
+func (s *NetworkSink) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		s.mu.Lock()
+		empty := len(s.queue) == 0
+		s.mu.Unlock()
+
+		if empty {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.done:
+			return net.ErrClosed
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close flushes the sink until the context is done, then closes the connection,
+// interrupting an entry being sent. The entries still buffered are dropped.
+func (s *NetworkSink) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.Flush(ctx)
+
+		s.mu.Lock()
+		s.cancel()
+		s.dropped.Add(uint64(len(s.queue)))
+		s.queue = nil
+		s.mu.Unlock()
+
+		<-s.done
+	})
+
+	return s.closeErr
+}
+
+// netConn is a connection, along with a channel closed when the peer closes it.
+type netConn struct {
+	net.Conn
+	lost chan struct{}
+	// stopClosing stops closing the connection when the sink is closed.
+	stopClosing func() bool
+}
+
+// run sends the buffered entries until the sink is closed.
+func (s *NetworkSink) run() {
+	defer close(s.done)
+
+	var conn *netConn
+	defer func() {
+		if conn != nil {
+			s.disconnect(conn)
+		}
+	}()
+
+	failures := 0
+	for {
+		var lost chan struct{}
+		if conn != nil {
+			lost = conn.lost
+		}
+
+		msg, ok := s.peek()
+		if !ok {
+			select {
+			case <-s.wake:
+			case <-lost:
+				s.disconnect(conn)
+				conn = nil
+			case <-s.ctx.Done():
+				return
+			}
+			continue
+		}
+
+		if conn == nil {
+			var err error
+			if conn, err = s.connect(); err != nil {
+				select {
+				case <-time.After(s.backoff(failures)):
+				case <-s.ctx.Done():
+					return
+				}
+				failures++
+				continue
+			}
+			failures = 0
+		}
+
+		select {
+		case <-conn.lost:
+			s.disconnect(conn)
+			conn = nil
+			continue
+		default:
+		}
+
+		if _, err := conn.Write(msg.data); err != nil {
+			s.disconnect(conn)
+			conn = nil
+			continue
+		}
+
+		s.sent.Add(1)
+		s.pop(msg.seq)
+	}
+}
+
+// peek returns the oldest buffered entry, if any.
+func (s *NetworkSink) peek() (netMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return netMessage{}, false
+	}
+
+	return s.queue[0], true
+}
+
+// pop removes the oldest buffered entry, unless it was dropped while being sent.
+func (s *NetworkSink) pop(seq uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) > 0 && s.queue[0].seq == seq {
+		s.queue = s.queue[1:]
+	}
+}
+
+// connect dials the peer. On stream connections, a goroutine watches for the
+// peer closing the connection, so that the sink reconnects before sending more.
+func (s *NetworkSink) connect() (*netConn, error) {
+	dialer := net.Dialer{Timeout: netDialTimeout}
+	c, err := dialer.DialContext(s.ctx, s.network, s.address)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.everConnected {
+		s.reconnects.Add(1)
+	}
+	s.everConnected = true
+	s.connected.Store(true)
+
+	// Closing the connection along with the sink unblocks a write to a peer
+	// that stopped reading.
+	conn := &netConn{Conn: c, lost: make(chan struct{}), stopClosing: context.AfterFunc(s.ctx, func() { _ = c.Close() })}
+	switch s.network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		go func() {
+			defer close(conn.lost)
+			_, _ = io.Copy(io.Discard, c)
+		}()
+	}
+
+	return conn, nil
+}
+
+// disconnect closes the connection.
+func (s *NetworkSink) disconnect(conn *netConn) {
+	s.connected.Store(false)
+	conn.stopClosing()
+	_ = conn.Close()
+}
+
+// backoff returns the delay before the next connection attempt, after the given
+// number of consecutive failures: a random duration between half and all of the
+// exponentially growing delay.
+func (s *NetworkSink) backoff(failures int) time.Duration {
+	d := s.minBackoff
+	for range failures {
+		if d >= s.maxBackoff/2 {
+			d = s.maxBackoff
+			break
+		}
+		d *= 2
+	}
+	d = min(d, s.maxBackoff)
+
+	if d <= 1 {
+		return d
+	}
+
+	return d/2 + rand.N(d/2)
+}
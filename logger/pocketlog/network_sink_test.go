@@ -0,0 +1,329 @@
+package pocketlog_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+// collector is an in-process stand-in for a collector agent, which sends the
+// lines it receives to a channel. It can be stopped and restarted on the same address.
+type collector struct {
+	t       *testing.T
+	network string
+	address string
+	lines   chan string
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    []net.Conn
+}
+
+// newCollector starts a collector listening on a local address of the network, "tcp" or "unix".
+func newCollector(t *testing.T, network string) *collector {
+	t.Helper()
+
+	address := "127.0.0.1:0"
+	if network == "unix" {
+		address = filepath.Join(t.TempDir(), "collector.sock")
+	}
+
+	c := &collector{t: t, network: network, address: address, lines: make(chan string, 100)}
+	c.start()
+	t.Cleanup(c.stop)
+
+	return c
+}
+
+// start listens on the collector's address.
+func (c *collector) start() {
+	c.t.Helper()
+
+	ln, err := net.Listen(c.network, c.address)
+	if err != nil {
+		c.t.Fatalf("unexpected error: %s", err)
+	}
+
+	c.mu.Lock()
+	c.listener = ln
+	c.address = ln.Addr().String()
+	c.mu.Unlock()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			c.mu.Lock()
+			c.conns = append(c.conns, conn)
+			c.mu.Unlock()
+
+			go func() {
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					c.lines <- scanner.Text()
+				}
+			}()
+		}
+	}()
+}
+
+// stop closes the listener and the connections accepted so far.
+func (c *collector) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.listener != nil {
+		_ = c.listener.Close()
+		c.listener = nil
+	}
+
+	for _, conn := range c.conns {
+		_ = conn.Close()
+	}
+	c.conns = nil
+}
+
+// next returns the next line received by the collector.
+func (c *collector) next() string {
+	c.t.Helper()
+
+	select {
+	case line := <-c.lines:
+		return line
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("timed out waiting for a line")
+		return ""
+	}
+}
+
+// waitFor polls the condition until it holds, failing the test after a while.
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestNetworkSink_reconnect(t *testing.T) {
+	for _, network := range []string{"tcp", "unix"} {
+		t.Run(network, func(t *testing.T) {
+			c := newCollector(t, network)
+
+			sink, err := pocketlog.NewNetworkSink(network, c.address, pocketlog.NetBackoff(time.Millisecond, 10*time.Millisecond))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSinks(sink), pocketlog.WithTimeLayout(""))
+			defer lgr.Close(context.Background())
+
+			lgr.Info("before")
+			if got := c.next(); got != "I - before" {
+				t.Errorf("invalid line, expected %q, got %q", "I - before", got)
+			}
+
+			// Entries are buffered while the collector is down.
+			c.stop()
+			waitFor(t, func() bool { return !sink.Stats().Connected })
+
+			lgr.Info("during 1")
+			lgr.Info("during 2")
+			waitFor(t, func() bool { return sink.Stats().Buffered == 2 })
+
+			c.start()
+
+			for _, want := range []string{"I - during 1", "I - during 2"} {
+				if got := c.next(); got != want {
+					t.Errorf("invalid line, expected %q, got %q", want, got)
+				}
+			}
+
+			if err := lgr.Flush(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			expected := pocketlog.NetworkStats{Sent: 3, Reconnects: 1, Connected: true}
+			if got := sink.Stats(); got != expected {
+				t.Errorf("invalid stats, expected %+v, got %+v", expected, got)
+			}
+		})
+	}
+}
+
+func TestNetworkSink_zeroBackoff(t *testing.T) {
+	c := newCollector(t, "unix")
+	c.stop()
+
+	// A zero backoff is raised to a millisecond, rather than retrying in a busy loop.
+	sink, err := pocketlog.NewNetworkSink("unix", c.address, pocketlog.NetBackoff(0, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSinks(sink), pocketlog.WithTimeLayout(""))
+	defer lgr.Close(context.Background())
+
+	lgr.Info("queued")
+	time.Sleep(20 * time.Millisecond)
+	c.start()
+
+	if got := c.next(); got != "I - queued" {
+		t.Errorf("invalid line, expected %q, got %q", "I - queued", got)
+	}
+}
+
+func TestNetworkSink_udp(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer pc.Close()
+
+	sink, err := pocketlog.NewNetworkSink("udp", pc.LocalAddr().String(),
+		pocketlog.NetFormatter(pocketlog.JSONFormatter{}),
+		pocketlog.NetLevel(pocketlog.LevelWarn),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSinks(sink), pocketlog.WithTimeLayout(""))
+	defer lgr.Close(context.Background())
+
+	// Below the sink's level.
+	lgr.Info(infoMessage)
+	lgr.Error(errorMessage, pocketlog.Int("code", 7))
+
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := `{"level":"error","msg":"` + errorMessage + `","code":7}` + "\n"
+	if got := string(buf[:n]); got != expected {
+		t.Errorf("invalid datagram, expected %q, got %q", expected, got)
+	}
+}
+
+func TestNetworkSink_closeStalledPeer(t *testing.T) {
+	// The peer accepts connections but never reads from them.
+	ln, err := net.Listen("unix", filepath.Join(t.TempDir(), "stalled.sock"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	sink, err := pocketlog.NewNetworkSink("unix", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSinks(sink), pocketlog.WithTimeLayout(""))
+	lgr.Info(infoMessage)
+
+	select {
+	case conn := <-accepted:
+		defer conn.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("the sink did not connect")
+	}
+
+	// Log until the socket buffers are full and the sink is stuck sending.
+	message := strings.Repeat("x", 64<<10)
+	waitFor(t, func() bool {
+		for range 100 {
+			lgr.Info(message)
+		}
+		sent := sink.Stats().Sent
+		time.Sleep(50 * time.Millisecond)
+		return sink.Stats().Sent == sent
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	closed := make(chan error, 1)
+	go func() { closed <- lgr.Close(ctx) }()
+
+	select {
+	case err := <-closed:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected Close to hit the deadline, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return once its context was done")
+	}
+}
+
+func TestNetworkSink_bufferFull(t *testing.T) {
+	// Nothing listens on the address of a closed listener.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	address := ln.Addr().String()
+	_ = ln.Close()
+
+	sink, err := pocketlog.NewNetworkSink("tcp", address, pocketlog.NetBufferSize(2), pocketlog.NetBackoff(time.Millisecond, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for range 5 {
+		if err := sink.WriteEntry(pocketlog.Entry{Level: pocketlog.LevelInfo, Message: infoMessage}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	expected := pocketlog.NetworkStats{Dropped: 3, Buffered: 2}
+	if got := sink.Stats(); got != expected {
+		t.Errorf("invalid stats, expected %+v, got %+v", expected, got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := sink.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the flush to time out, got %v", err)
+	}
+
+	// Entries still buffered when the sink is closed are dropped.
+	if got := sink.Dropped(); got != 5 {
+		t.Errorf("expected 5 dropped entries, got %d", got)
+	}
+
+	if err := sink.WriteEntry(pocketlog.Entry{Level: pocketlog.LevelInfo, Message: infoMessage}); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("expected net.ErrClosed, got %v", err)
+	}
+}
+
+func TestNewNetworkSink_unsupportedNetwork(t *testing.T) {
+	if _, err := pocketlog.NewNetworkSink("ip4:icmp", "127.0.0.1"); err == nil {
+		t.Error("expected an error")
+	}
+}
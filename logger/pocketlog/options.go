@@ -1,6 +1,10 @@
 package pocketlog
 
-import "io"
+import (
+	"io"
+	"log/slog"
+	"time"
+)
 
 // Option defines a functional option to our Logger.
 // See https://golang.cafe/blog/golang-functional-options-pattern
@@ -19,3 +23,231 @@ func WithMaxLen(length int) Option {
 		l.maxLen = length
 	}
 }
+
+// WithFormatter returns a configuration function that sets the formatter used to encode entries.
+func WithFormatter(formatter Formatter) Option {
+	return func(l *Logger) {
+		l.formatter = formatter
+	}
+}
+
+// WithJSON returns a configuration function that makes the logger print each
+// line as a JSON object holding the time, level, message and fields.
+// It is a shorthand for WithFormatter(JSONFormatter{}).
+func WithJSON() Option {
+	return WithFormatter(JSONFormatter{})
+}
+
+// WithClock returns a configuration function that sets the clock used to timestamp entries.
+func WithClock(clock Clock) Option {
+	return func(l *Logger) {
+		l.clock = clock
+	}
+}
+
+// WithTimeLayout returns a configuration function that sets the layout of timestamps,
+// such as TimeLayoutRFC3339Nano, TimeLayoutUnixMilli or any time.Time.Format layout.
+// An empty layout disables timestamps.
+func WithTimeLayout(layout string) Option {
+	return func(l *Logger) {
+		l.timeLayout = layout
+	}
+}
+
+// WithUTC returns a configuration function that prints timestamps in UTC.
+func WithUTC() Option {
+	return func(l *Logger) {
+		l.location = time.UTC
+	}
+}
+
+// WithLocalTime returns a configuration function that prints timestamps in the local time zone.
+func WithLocalTime() Option {
+	return func(l *Logger) {
+		l.location = time.Local
+	}
+}
+
+// WithCaller returns a configuration function that records the file, line and
+// function of the call site on every entry.
+func WithCaller() Option {
+	return func(l *Logger) {
+		l.addCaller = true
+	}
+}
+
+// WithAsync returns a configuration function that makes the logger asynchronous:
+// entries are queued in a buffer of the given size and written by a background
+// goroutine, and the policy decides what happens when the buffer is full.
+// Call Close before exiting so that queued entries are not lost.
+func WithAsync(bufferSize int, policy OverflowPolicy) Option {
+	return func(l *Logger) {
+		l.asyncOpts.enabled = true
+		l.asyncOpts.size = bufferSize
+		l.asyncOpts.policy = policy
+	}
+}
+
+// WithAsyncDropLevel returns a configuration function that sets the level under
+// which entries are dropped by the OverflowDropBelowLevel policy. It defaults to LevelError.
+func WithAsyncDropLevel(level Level) Option {
+	return func(l *Logger) {
+		l.asyncOpts.dropLevel = level
+	}
+}
+
+// WithExitFunc returns a configuration function that replaces os.Exit as the
+// function Fatal and Fatalf call once the entry is written, e.g. in tests.
+func WithExitFunc(exit func(code int)) Option {
+	return func(l *Logger) {
+		l.exit = exit
+	}
+}
+
+// WithAtomicLevel returns a configuration function that makes the logger use
+// the given threshold, instead of the one passed to New. The threshold can be
+// shared by several loggers, and changed while they are in use.
+func WithAtomicLevel(level *AtomicLevel) Option {
+	return func(l *Logger) {
+		l.level = level
+	}
+}
+
+// WithLevelRegistry returns a configuration function that makes named loggers
+// use the thresholds of the registry. Loggers whose name has no threshold in
+// the registry keep using the logger's.
+func WithLevelRegistry(registry *LevelRegistry) Option {
+	return func(l *Logger) {
+		l.levels = registry
+	}
+}
+
+// WithSlogHandler returns a configuration function that makes the logger emit
+// entries through the given slog.Handler, instead of formatting them to its output.
+// It is a shorthand for WithSinks(NewSlogSink(handler)).
+func WithSlogHandler(handler slog.Handler) Option {
+	return WithSinks(NewSlogSink(handler))
+}
+
+// WithSinks returns a configuration function that makes the logger write to the
+// given sinks, instead of formatting entries to its output.
+func WithSinks(sinks ...Sink) Option {
+	return func(l *Logger) {
+		l.sinks = append(l.sinks, sinks...)
+	}
+}
+
+// WithSampling returns a configuration function that samples entries sharing a
+// message template, the format of printf-style methods or the message of
+// structured ones: in each interval, the first entries are written, then every
+// thereafter-th one. A thereafter of zero drops all entries after the first ones.
+// A warning summarizing the suppressed entries is written per template when an
+// interval is over, and by Flush and Close.
+func WithSampling(interval time.Duration, first, thereafter int) Option {
+	return func(l *Logger) {
+		l.samplingOpts.interval = interval
+		l.samplingOpts.first = first
+		l.samplingOpts.thereafter = thereafter
+	}
+}
+
+// WithRateLimit returns a configuration function that limits the entries of the
+// given level to perSecond on average, allowing bursts of burst entries.
+// Suppressed entries are summarized like those dropped by sampling, every
+// sampling interval or every minute if sampling is disabled.
+func WithRateLimit(level Level, perSecond float64, burst int) Option {
+	return func(l *Logger) {
+		if l.samplingOpts.rateLimits == nil {
+			l.samplingOpts.rateLimits = make(map[Level]rateLimit)
+		}
+		l.samplingOpts.rateLimits[level] = rateLimit{perSecond: perSecond, burst: burst}
+	}
+}
+
+// WithDedup returns a configuration function that collapses identical
+// consecutive entries, with the same level, message and fields, logged within
+// the window: the first one is written, and the others are replaced by a
+// "last message repeated N times" line. That line is written when a different
+// entry arrives, when the window is over, or by Flush and Close.
+func WithDedup(window time.Duration) Option {
+	return func(l *Logger) {
+		l.dedupWindow = window
+	}
+}
+
+// WithContextExtractors returns a configuration function that attaches the
+// fields returned by the extractors to the entries logged with a context, by
+// the *Context methods and the slog handler.
+func WithContextExtractors(extractors ...ContextExtractor) Option {
+	return func(l *Logger) {
+		l.extractors = append(l.extractors, extractors...)
+	}
+}
+
+// WithColor returns a configuration function that decides whether the levels
+// printed to the output are colorized: a TextFormatter is replaced by a
+// ColorTextFormatter when colors are enabled, and conversely. With ColorAuto,
+// colors are enabled if the output is a terminal, which is only detected on
+// Linux, and the NO_COLOR environment variable is empty.
+func WithColor(mode ColorMode) Option {
+	return func(l *Logger) {
+		l.color = &mode
+	}
+}
+
+// WithRedactedKeys returns a configuration function that masks the values of
+// the fields whose key is one of the given ones, such as "password" or
+// "authorization", regardless of case. The values of groups are masked whole.
+func WithRedactedKeys(keys ...string) Option {
+	return func(l *Logger) {
+		l.redactOpts.keys = append(l.redactOpts.keys, keys...)
+	}
+}
+
+// WithRedactedPatterns returns a configuration function that replaces the data
+// matched by the patterns, such as EmailPattern, in messages, printf-style
+// ones included, and in field values other than numbers, booleans and times,
+// which are replaced by their redacted text or JSON encoding when they match.
+func WithRedactedPatterns(patterns ...Pattern) Option {
+	return func(l *Logger) {
+		l.redactOpts.patterns = append(l.redactOpts.patterns, patterns...)
+	}
+}
+
+// WithErrorHandler returns a configuration function that sets the function
+// called when an entry could not be written. Without it, failures are only
+// counted, see Logger.WriteStats.
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(l *Logger) {
+		l.diag.handler = handler
+	}
+}
+
+// WithFallbackOutput returns a configuration function that sets an output, such
+// as os.Stderr, the entries are written to when a sink fails to write them.
+// Entries are formatted with the logger's formatter.
+func WithFallbackOutput(output io.Writer) Option {
+	return func(l *Logger) {
+		l.diag.fallback = output
+	}
+}
+
+// WithHook returns a configuration function that registers a hook for the
+// entries of the given levels, or of every level if none is given. Hooks run in
+// registration order, before sampling; an entry dropped by a hook is neither
+// passed to the following ones nor written. Entries produced by the logger
+// itself, such as sampling summaries, do not go through hooks. Hooks receive
+// redacted entries, and the entries they return are redacted again.
+func WithHook(hook Hook, levels ...Level) Option {
+	return func(l *Logger) {
+		h := registeredHook{hook: hook}
+		for _, level := range levels {
+			h.levels |= 1 << level
+		}
+		if len(levels) == 0 {
+			h.levels = 1<<(maxLevel+1) - 1
+		}
+
+		l.hooks = append(l.hooks, h)
+	}
+}
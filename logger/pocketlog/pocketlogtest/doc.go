@@ -0,0 +1,13 @@
+/*
+Package pocketlogtest helps testing code that logs with pocketlog.
+
+NewLogger returns a logger recording its entries, for assertions, and printing
+them with testing.T.Log, so that they only show up for failing or verbose tests:
+
+	lgr, rec := pocketlogtest.NewLogger(t)
+	serve(lgr)
+	rec.AssertLogged(t, pocketlog.LevelInfo, "request served", pocketlog.Int("status", 200))
+
+FailOnError makes a test fail on unexpected entries of level error or higher.
+*/
+package pocketlogtest
@@ -0,0 +1,234 @@
+package pocketlogtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+// Recorder is a pocketlog.Sink keeping the entries it receives.
+// It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []pocketlog.Entry
+}
+
+// NewRecorder returns an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// WriteEntry implements pocketlog.Sink.
+func (r *Recorder) WriteEntry(e pocketlog.Entry) error {
+	e.Fields = slices.Clone(e.Fields)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, e)
+
+	return nil
+}
+
+// Entries returns the entries recorded so far.
+func (r *Recorder) Entries() []pocketlog.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.entries)
+}
+
+// Reset forgets the entries recorded so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+}
+
+// Find returns the recorded entries of the level whose message contains the
+// given substring, and which hold the given fields, among others.
+func (r *Recorder) Find(level pocketlog.Level, message string, fields ...pocketlog.Field) []pocketlog.Entry {
+	var found []pocketlog.Entry
+	for _, e := range r.Entries() {
+		if Matches(e, level, message, fields...) {
+			found = append(found, e)
+		}
+	}
+
+	return found
+}
+
+// AssertLogged fails the test unless an entry of the level, whose message
+// contains the given substring, and which holds the given fields was recorded.
+func (r *Recorder) AssertLogged(t testing.TB, level pocketlog.Level, message string, fields ...pocketlog.Field) {
+	t.Helper()
+
+	if len(r.Find(level, message, fields...)) == 0 {
+		t.Errorf("expected an entry %s, got:\n%s", describe(level, message, fields), r.dump())
+	}
+}
+
+// AssertNotLogged fails the test if an entry of the level, whose message
+// contains the given substring, and which holds the given fields was recorded.
+func (r *Recorder) AssertNotLogged(t testing.TB, level pocketlog.Level, message string, fields ...pocketlog.Field) {
+	t.Helper()
+
+	if found := r.Find(level, message, fields...); len(found) > 0 {
+		t.Errorf("expected no entry %s, got:\n%s", describe(level, message, fields), dumpEntries(found))
+	}
+}
+
+// dump describes the recorded entries, one per line.
+func (r *Recorder) dump() string {
+	return dumpEntries(r.Entries())
+}
+
+// Matches tells whether the entry is of the level, its message contains the
+// given substring, and it holds the given fields, among others. Fields are
+// compared with reflect.DeepEqual, and groups must match whole.
+func Matches(e pocketlog.Entry, level pocketlog.Level, message string, fields ...pocketlog.Field) bool {
+	if e.Level != level || !strings.Contains(e.Message, message) {
+		return false
+	}
+
+	for _, want := range fields {
+		if !slices.ContainsFunc(e.Fields, func(f pocketlog.Field) bool {
+			return f.Key == want.Key && reflect.DeepEqual(f.Value, want.Value)
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// describe describes the expectations of an assertion.
+func describe(level pocketlog.Level, message string, fields []pocketlog.Field) string {
+	desc := fmt.Sprintf("of level %s containing %q", level.Name(), message)
+	if len(fields) > 0 {
+		desc += " with fields " + formatFields(fields)
+	}
+
+	return desc
+}
+
+// dumpEntries describes the entries, one per line.
+func dumpEntries(entries []pocketlog.Entry) string {
+	if len(entries) == 0 {
+		return "\t(no entries)"
+	}
+
+	var sb strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "\t%s %q %s", e.Level.Name(), e.Message, formatFields(e.Fields))
+	}
+
+	return sb.String()
+}
+
+// formatFields describes fields as a list of key=value pairs.
+func formatFields(fields []pocketlog.Field) string {
+	pairs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", f.Key, f.Value))
+	}
+
+	return "[" + strings.Join(pairs, " ") + "]"
+}
+
+// errorSink is a pocketlog.Sink failing a test on unexpected entries of level error or higher.
+type errorSink struct {
+	t       testing.TB
+	allowed []string
+}
+
+// FailOnError returns a sink failing the test on every entry of level error or
+// higher, unless its message contains one of the allowed substrings.
+func FailOnError(t testing.TB, allowed ...string) pocketlog.Sink {
+	return errorSink{t: t, allowed: allowed}
+}
+
+// WriteEntry implements pocketlog.Sink.
+func (s errorSink) WriteEntry(e pocketlog.Entry) error {
+	if e.Level < pocketlog.LevelError {
+		return nil
+	}
+
+	for _, allowed := range s.allowed {
+		if strings.Contains(e.Message, allowed) {
+			return nil
+		}
+	}
+
+	s.t.Errorf("unexpected %s entry: %q %s", e.Level.Name(), e.Message, formatFields(e.Fields))
+
+	return nil
+}
+
+// testWriter writes each line through testing.TB.Log.
+type testWriter struct {
+	t testing.TB
+
+	// mu guards done, set once the test is over: logging then panics.
+	mu   sync.Mutex
+	done bool
+}
+
+// Writer returns an io.Writer printing each line written to it with t.Log.
+// Lines written after the test is over are discarded.
+func Writer(t testing.TB) io.Writer {
+	w := &testWriter{t: t}
+	t.Cleanup(func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		w.done = true
+	})
+
+	return w
+}
+
+// Write implements io.Writer.
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.done {
+		w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	}
+
+	return len(p), nil
+}
+
+// NewLogger returns a logger at the trace level, recording its entries and
+// printing them with t.Log, along with its recorder. Options are applied after
+// the defaults, and sinks added with pocketlog.WithSinks receive the entries
+// too. The logger is closed when the test is over.
+func NewLogger(t testing.TB, opts ...pocketlog.Option) (*pocketlog.Logger, *Recorder) {
+	rec := NewRecorder()
+
+	defaults := []pocketlog.Option{
+		pocketlog.WithSinks(rec, pocketlog.NewSink(Writer(t))),
+		pocketlog.WithExitFunc(func(code int) {
+			t.Errorf("logger exited with status %d", code)
+		}),
+	}
+	lgr := pocketlog.New(pocketlog.LevelTrace, append(defaults, opts...)...)
+
+	t.Cleanup(func() {
+		_ = lgr.Close(context.Background())
+	})
+
+	return lgr, rec
+}
@@ -0,0 +1,135 @@
+package pocketlogtest_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog/pocketlogtest"
+)
+
+// fakeT records the failures and logs of a test instead of reporting them.
+type fakeT struct {
+	testing.TB
+
+	mu       sync.Mutex
+	failures []string
+	logs     []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Log(args ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func TestRecorder_AssertLogged(t *testing.T) {
+	ft := &fakeT{TB: t}
+	lgr, rec := pocketlogtest.NewLogger(ft)
+
+	lgr.Named("http").Info("request served", pocketlog.String("path", "/orders"), pocketlog.Int("status", 200))
+	lgr.Warnf("slow request: %dms", 1200)
+
+	rec.AssertLogged(ft, pocketlog.LevelInfo, "served", pocketlog.Int("status", 200))
+	rec.AssertLogged(ft, pocketlog.LevelWarn, "slow request")
+	rec.AssertNotLogged(ft, pocketlog.LevelError, "")
+	if len(ft.failures) > 0 {
+		t.Fatalf("unexpected failures: %q", ft.failures)
+	}
+
+	rec.AssertLogged(ft, pocketlog.LevelInfo, "served", pocketlog.Int("status", 404))
+	rec.AssertLogged(ft, pocketlog.LevelError, "served")
+	rec.AssertNotLogged(ft, pocketlog.LevelWarn, "slow")
+
+	expected := []string{
+		`expected an entry of level info containing "served" with fields [status=404], got:` + "\n" +
+			`	info "request served" [path=/orders status=200]` + "\n" +
+			`	warn "slow request: 1200ms" []`,
+		`expected an entry of level error containing "served", got:` + "\n" +
+			`	info "request served" [path=/orders status=200]` + "\n" +
+			`	warn "slow request: 1200ms" []`,
+		`expected no entry of level warn containing "slow", got:` + "\n" +
+			`	warn "slow request: 1200ms" []`,
+	}
+	if got := strings.Join(ft.failures, "\n--\n"); got != strings.Join(expected, "\n--\n") {
+		t.Errorf("invalid failures, expected %q, got %q", expected, ft.failures)
+	}
+}
+
+func TestRecorder_Entries(t *testing.T) {
+	rec := pocketlogtest.NewRecorder()
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSinks(rec))
+
+	fields := []pocketlog.Field{pocketlog.String("user", "ada")}
+	lgr.Info(infoMessage, fields...)
+	// The recorder keeps its own copy of the fields.
+	fields[0] = pocketlog.String("user", "bob")
+
+	entries := rec.Entries()
+	if len(entries) != 1 || entries[0].Message != infoMessage || entries[0].Fields[0].Value != "ada" {
+		t.Errorf("invalid entries: %+v", entries)
+	}
+
+	if found := rec.Find(pocketlog.LevelInfo, "", pocketlog.String("user", "ada")); len(found) != 1 {
+		t.Errorf("expected 1 entry, got %d", len(found))
+	}
+
+	rec.Reset()
+	if entries := rec.Entries(); len(entries) != 0 {
+		t.Errorf("expected no entries, got %+v", entries)
+	}
+}
+
+func TestFailOnError(t *testing.T) {
+	ft := &fakeT{TB: t}
+	lgr, _ := pocketlogtest.NewLogger(ft, pocketlog.WithSinks(pocketlogtest.FailOnError(ft, "expected timeout")))
+
+	lgr.Warn("retrying")
+	lgr.Error("expected timeout after 5s")
+	lgr.Error("connection refused", pocketlog.Int("port", 5432))
+
+	expected := []string{`unexpected error entry: "connection refused" [port=5432]`}
+	if strings.Join(ft.failures, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("invalid failures, expected %q, got %q", expected, ft.failures)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	ft := &fakeT{TB: t}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(pocketlogtest.Writer(ft)), pocketlog.WithTimeLayout(""))
+
+	lgr.Info(infoMessage)
+	lgr.Debug("second line")
+
+	expected := []string{"I - " + infoMessage, "D - second line"}
+	if strings.Join(ft.logs, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("invalid logs, expected %q, got %q", expected, ft.logs)
+	}
+}
+
+func TestNewLogger_fatal(t *testing.T) {
+	ft := &fakeT{TB: t}
+	lgr, rec := pocketlogtest.NewLogger(ft)
+
+	lgr.Fatal("cannot start")
+
+	rec.AssertLogged(t, pocketlog.LevelFatal, "cannot start")
+	expected := []string{"logger exited with status 1"}
+	if strings.Join(ft.failures, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("invalid failures, expected %q, got %q", expected, ft.failures)
+	}
+}
+
+const infoMessage = "Keep an eye on the gophers"
@@ -0,0 +1,242 @@
+package pocketlog
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// redactedValue replaces the values of redacted fields.
+const redactedValue = "[REDACTED]"
+
+// Redactor is implemented by types that control how they are logged, e.g. to
+// hide a secret. Values implementing it, whether passed as fields or as
+// arguments of printf-style methods, are logged as the value Redact returns.
+type Redactor interface {
+	Redact() any
+}
+
+// Pattern matches sensitive data in messages and field values.
+type Pattern struct {
+	// Regexp finds the candidates.
+	Regexp *regexp.Regexp
+	// Valid, if set, tells whether a candidate is actually sensitive.
+	Valid func(match string) bool
+	// Replacement replaces the sensitive matches. It may refer to the groups
+	// of Regexp, as in regexp.Regexp.Expand. It defaults to "[REDACTED]".
+	Replacement string
+}
+
+// Built-in patterns, for use with WithRedactedPatterns.
+var (
+	// EmailPattern matches email addresses.
+	EmailPattern = Pattern{
+		Regexp: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+	}
+	// BearerTokenPattern matches bearer tokens, such as those of Authorization headers,
+	// keeping the scheme.
+	BearerTokenPattern = Pattern{
+		Regexp:      regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9\-._~+/]+=*`),
+		Replacement: "$1 " + redactedValue,
+	}
+	// CreditCardPattern matches credit card numbers of 13 to 19 digits, possibly
+	// grouped with spaces or dashes, that pass the Luhn check.
+	CreditCardPattern = Pattern{
+		Regexp: regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		Valid:  luhnValid,
+	}
+)
+
+// redactionOptions holds the settings of redaction until the logger is created.
+type redactionOptions struct {
+	keys     []string
+	patterns []Pattern
+}
+
+// redaction masks the values of sensitive keys, and sensitive data in messages and values.
+type redaction struct {
+	keys     map[string]struct{}
+	patterns []Pattern
+}
+
+// newRedaction returns a redaction, or nil if the options set neither keys nor patterns.
+func newRedaction(opts redactionOptions) *redaction {
+	if len(opts.keys) == 0 && len(opts.patterns) == 0 {
+		return nil
+	}
+
+	r := &redaction{keys: make(map[string]struct{}, len(opts.keys)), patterns: opts.patterns}
+	for _, key := range opts.keys {
+		r.keys[strings.ToLower(key)] = struct{}{}
+	}
+
+	return r
+}
+
+// redactString replaces the sensitive data matched by the patterns.
+func (r *redaction) redactString(s string) string {
+	if r == nil {
+		return s
+	}
+
+	for _, p := range r.patterns {
+		s = p.replace(s)
+	}
+
+	return s
+}
+
+// redactEntry redacts the message and the fields of the entry.
+func (r *redaction) redactEntry(e Entry) Entry {
+	e.Message = r.redactString(e.Message)
+	e.Fields = r.redactFields(e.Fields)
+
+	return e
+}
+
+// redactFields returns the fields with the values of sensitive keys masked,
+// sensitive data replaced in values, and Redactor values replaced by
+// what they return. The fields are only copied if one of them changes.
+func (r *redaction) redactFields(fields []Field) []Field {
+	var redacted []Field
+	for i, f := range fields {
+		value, changed := r.redactValue(f.Key, f.Value)
+		if redacted == nil {
+			if !changed {
+				continue
+			}
+			redacted = append(make([]Field, 0, len(fields)), fields[:i]...)
+		}
+		redacted = append(redacted, Field{Key: f.Key, Value: value})
+	}
+
+	if redacted == nil {
+		return fields
+	}
+
+	return redacted
+}
+
+// redactValue returns the value of the field to log, and whether it differs from the given one.
+func (r *redaction) redactValue(key string, value any) (any, bool) {
+	if r != nil {
+		if _, ok := r.keys[strings.ToLower(key)]; ok {
+			return redactedValue, true
+		}
+	}
+
+	changed := false
+	if v, ok := value.(Redactor); ok {
+		value, changed = v.Redact(), true
+	}
+
+	switch v := value.(type) {
+	case []Field:
+		group := r.redactFields(v)
+		return group, changed || len(group) > 0 && &group[0] != &v[0]
+	case string:
+		s := r.redactString(v)
+		return s, changed || s != v
+	case nil, bool, int, int64, uint64, float64, time.Time, time.Duration:
+		return value, changed
+	default:
+		// Other values, such as errors, structs, slices and maps, are
+		// encoded by the formatters, so their encodings are redacted instead.
+		if r == nil || len(r.patterns) == 0 {
+			return value, changed
+		}
+
+		s := formatValue(v)
+		if redacted := r.redactString(s); redacted != s {
+			return redacted, true
+		}
+		if encoded, err := json.Marshal(v); err == nil {
+			if redacted := r.redactString(string(encoded)); redacted != string(encoded) {
+				return redacted, true
+			}
+		}
+	}
+
+	return value, changed
+}
+
+// redactArgs replaces the arguments implementing Redactor by what they return.
+// The arguments are only copied if one of them implements it.
+func redactArgs(args []any) []any {
+	var redacted []any
+	for i, arg := range args {
+		v, ok := arg.(Redactor)
+		if !ok {
+			if redacted != nil {
+				redacted = append(redacted, arg)
+			}
+			continue
+		}
+
+		if redacted == nil {
+			redacted = append(make([]any, 0, len(args)), args[:i]...)
+		}
+		redacted = append(redacted, v.Redact())
+	}
+
+	if redacted == nil {
+		return args
+	}
+
+	return redacted
+}
+
+// replace replaces the sensitive matches of the pattern in s.
+func (p Pattern) replace(s string) string {
+	matches := p.Regexp.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+
+	replacement := p.Replacement
+	if replacement == "" {
+		replacement = redactedValue
+	}
+
+	var buf []byte
+	last := 0
+	for _, m := range matches {
+		if p.Valid != nil && !p.Valid(s[m[0]:m[1]]) {
+			continue
+		}
+
+		buf = append(buf, s[last:m[0]]...)
+		buf = p.Regexp.ExpandString(buf, replacement, s, m)
+		last = m[1]
+	}
+
+	if last == 0 {
+		return s
+	}
+
+	return string(append(buf, s[last:]...))
+}
+
+// luhnValid tells whether the digits of s pass the Luhn check, other characters being ignored.
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+
+	return n > 0 && sum%10 == 0
+}
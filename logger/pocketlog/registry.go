@@ -0,0 +1,132 @@
+package pocketlog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// LevelRegistry holds thresholds for named loggers.
+// A threshold set for a name applies to the loggers of that name and to their
+// descendants, unless one of them has its own. The empty name is the root of
+// every logger.
+type LevelRegistry struct {
+	mu     sync.RWMutex
+	levels map[string]Level
+}
+
+// NewLevelRegistry returns an empty registry.
+func NewLevelRegistry() *LevelRegistry {
+	return &LevelRegistry{levels: make(map[string]Level)}
+}
+
+// ParseLevelSpec returns a registry holding the thresholds of the spec.
+// See LevelRegistry.SetSpec for the syntax.
+func ParseLevelSpec(spec string) (*LevelRegistry, error) {
+	r := NewLevelRegistry()
+	if err := r.SetSpec(spec); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// SetLevel sets the threshold of the loggers of the given name and their descendants.
+func (r *LevelRegistry) SetLevel(name string, level Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.levels[name] = level
+}
+
+// Unset removes the threshold of the given name, which then inherits from its ancestors.
+func (r *LevelRegistry) Unset(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.levels, name)
+}
+
+// Lookup returns the threshold of the logger of the given name: its own, or
+// that of its closest ancestor. It returns false if none is set.
+func (r *LevelRegistry) Lookup(name string) (Level, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for {
+		if level, ok := r.levels[name]; ok {
+			return level, true
+		}
+
+		if name == "" {
+			return 0, false
+		}
+
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			name = ""
+		} else {
+			name = name[:i]
+		}
+	}
+}
+
+// SetSpec replaces the thresholds of the registry with those of the spec, a
+// comma-separated list of name=level pairs such as "db=debug,http=error".
+// A level without a name applies to the root. Levels are read with ParseLevel.
+func (r *LevelRegistry) SetSpec(spec string) error {
+	levels := make(map[string]Level)
+
+	for item := range strings.SplitSeq(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, levelText, found := strings.Cut(item, "=")
+		if !found {
+			name, levelText = "", item
+		}
+
+		level, err := ParseLevel(levelText)
+		if err != nil {
+			return fmt.Errorf("invalid level spec %q: %w", item, err)
+		}
+
+		levels[strings.TrimSpace(name)] = level
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.levels = levels
+	return nil
+}
+
+// String returns the spec of the registry, with names sorted.
+func (r *LevelRegistry) String() string {
+	if r == nil {
+		return ""
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := make([]string, 0, len(r.levels))
+	for name, level := range r.levels {
+		if name == "" {
+			items = append(items, level.Name())
+		} else {
+			items = append(items, name+"="+level.Name())
+		}
+	}
+	slices.Sort(items)
+
+	return strings.Join(items, ",")
+}
+
+// Set implements flag.Value, replacing the thresholds with those of the spec.
+func (r *LevelRegistry) Set(spec string) error {
+	return r.SetSpec(spec)
+}
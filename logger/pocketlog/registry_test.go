@@ -0,0 +1,114 @@
+package pocketlog_test
+
+import (
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+func TestLevelRegistry_Lookup(t *testing.T) {
+	registry, err := pocketlog.ParseLevelSpec(" warn, db=debug ,db.pool=trace,http=error")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tt := map[string]pocketlog.Level{
+		"":              pocketlog.LevelWarn,
+		"db":            pocketlog.LevelDebug,
+		"db.pool":       pocketlog.LevelTrace,
+		"db.pool.conn":  pocketlog.LevelTrace,
+		"db.migrations": pocketlog.LevelDebug,
+		"http":          pocketlog.LevelError,
+		"http2":         pocketlog.LevelWarn,
+		"cache.lru":     pocketlog.LevelWarn,
+	}
+
+	for name, want := range tt {
+		got, ok := registry.Lookup(name)
+		if !ok || got != want {
+			t.Errorf("invalid level for %q, expected %v, got %v (%t)", name, want, got, ok)
+		}
+	}
+
+	if expected := "db.pool=trace,db=debug,http=error,warn"; registry.String() != expected {
+		t.Errorf("invalid spec, expected %q, got %q", expected, registry.String())
+	}
+
+	registry.Unset("")
+	if _, ok := registry.Lookup("cache"); ok {
+		t.Error("expected no level once the root is unset")
+	}
+}
+
+func TestLevelRegistry_InvalidSpec(t *testing.T) {
+	registry, err := pocketlog.ParseLevelSpec("db=debug")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&testWriter{})
+	fs.Var(registry, "log-levels", "per-module logging thresholds")
+
+	if err := fs.Parse([]string{"-log-levels", "db=debug,http=loud"}); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+
+	if err := registry.SetSpec("db=loud"); !errors.Is(err, pocketlog.ErrUnknownLevel) {
+		t.Errorf("expected ErrUnknownLevel, got %v", err)
+	}
+
+	// A failed update leaves the registry untouched.
+	if got := registry.String(); got != "db=debug" {
+		t.Errorf("invalid spec, expected %q, got %q", "db=debug", got)
+	}
+}
+
+func TestLogger_Named(t *testing.T) {
+	registry, err := pocketlog.ParseLevelSpec("db=debug,http=error")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""), pocketlog.WithLevelRegistry(registry))
+
+	pool := lgr.Named("db").Named("pool")
+	http := lgr.Named("http")
+	cache := lgr.Named("cache")
+
+	pool.Debugf(debugMessage)
+	http.Infof(infoMessage)
+	http.Errorf(errorMessage)
+	cache.Debugf(debugMessage)
+	cache.Infof(infoMessage)
+
+	expected := "D - [db.pool] " + debugMessage + "\n" +
+		"E - [http] " + errorMessage + "\n" +
+		"I - [cache] " + infoMessage + "\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+
+	// Thresholds can be changed at runtime.
+	registry.SetLevel("http", pocketlog.LevelInfo)
+	tw.contents = ""
+	http.Infof(infoMessage)
+	if expected := "I - [http] " + infoMessage + "\n"; tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_NamedJSON(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""), pocketlog.WithJSON())
+
+	lgr.Named("db").Named("").Named("pool").Infof(infoMessage)
+
+	expected := `{"level":"info","logger":"db.pool","msg":"` + infoMessage + `"}` + "\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
@@ -0,0 +1,435 @@
+package pocketlog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeLayout is the layout of the timestamp in the names of backups.
+const backupTimeLayout = "20060102T150405.000"
+
+// rotateRetryDelay is the time a RotatingFile waits before trying to rotate
+// again, after failing to move the file to a backup.
+const rotateRetryDelay = 10 * time.Second
+
+// RotatingFile is an io.Writer appending to a file, which it rotates by size
+// and/or time. Rotated files are kept next to it as backups named after the
+// file and the time of the rotation, such as app-20060102T150405.000.log.
+// It is safe for concurrent use.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	interval   time.Duration
+	compress   bool
+	maxBackups int
+	maxAge     time.Duration
+	clock      Clock
+
+	// mu guards the fields below. file is nil once closed, or if it could
+	// not be reopened, in which case writes try to open it again.
+	mu       sync.Mutex
+	file     *os.File
+	closed   bool
+	size     int64
+	openedAt time.Time
+	retryAt  time.Time
+
+	// cleanupMu serializes the compression and removal of backups.
+	cleanupMu sync.Mutex
+	cleanups  sync.WaitGroup
+}
+
+// FileOption defines a functional option to a RotatingFile.
+type FileOption func(*RotatingFile)
+
+// FileMaxSize returns a configuration function that rotates the file before
+// a write would make it larger than the given number of bytes.
+func FileMaxSize(bytes int64) FileOption {
+	return func(f *RotatingFile) {
+		f.maxSize = bytes
+	}
+}
+
+// FileRotateEvery returns a configuration function that rotates the file once
+// it has been written to for the given duration.
+func FileRotateEvery(interval time.Duration) FileOption {
+	return func(f *RotatingFile) {
+		f.interval = interval
+	}
+}
+
+// FileCompress returns a configuration function that gzips backups in the background.
+func FileCompress() FileOption {
+	return func(f *RotatingFile) {
+		f.compress = true
+	}
+}
+
+// FileMaxBackups returns a configuration function that sets the number of
+// backups to keep, the oldest being removed first. Zero keeps them all.
+func FileMaxBackups(n int) FileOption {
+	return func(f *RotatingFile) {
+		f.maxBackups = n
+	}
+}
+
+// FileMaxAge returns a configuration function that removes backups older than
+// the given duration. Zero keeps them regardless of their age.
+func FileMaxAge(age time.Duration) FileOption {
+	return func(f *RotatingFile) {
+		f.maxAge = age
+	}
+}
+
+// FileClock returns a configuration function that sets the clock used to
+// schedule rotations and name backups.
+func FileClock(clock Clock) FileOption {
+	return func(f *RotatingFile) {
+		f.clock = clock
+	}
+}
+
+// NewRotatingFile opens, or creates, the file at path for appending.
+// Without options, the file is never rotated.
+func NewRotatingFile(path string, opts ...FileOption) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, clock: systemClock{}}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Write implements io.Writer, rotating the file first if needed. If the
+// rotation fails, p is still written to the current file, and the error of
+// the rotation is returned along with len(p).
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	var rotateErr error
+	if f.file != nil && f.shouldRotate(len(p)) {
+		rotateErr = f.rotate()
+	}
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, errors.Join(rotateErr, err)
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, errors.Join(rotateErr, err)
+	}
+
+	return n, rotateErr
+}
+
+// Rotate moves the current file to a backup, and starts a new one.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.file == nil {
+		return f.open()
+	}
+
+	return f.rotate()
+}
+
+// Reopen closes and reopens the file at the same path. Tools such as logrotate
+// move the file away then signal the program, which must reopen it. If the
+// file can't be reopened, the following writes try again.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	return errors.Join(f.closeFile(), f.open())
+}
+
+// ReopenOnSignal reopens the file each time one of the signals is received,
+// SIGHUP if none is given on Unix systems. Elsewhere, there is no default and
+// nothing is listened to without signals. Errors are ignored: writes report them.
+// The returned function stops listening to the signals.
+func (f *RotatingFile) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = defaultReopenSignals
+	}
+	if len(sigs) == 0 {
+		return func() {}
+	}
+
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, sigs...)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-received:
+				_ = f.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(received)
+			close(done)
+		})
+	}
+}
+
+// Close closes the file, and waits for the compression and removal of backups to finish.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if !f.closed {
+		err = f.closeFile()
+		f.closed = true
+	}
+	f.mu.Unlock()
+
+	f.cleanups.Wait()
+
+	return err
+}
+
+// open opens the file at path for appending. f.mu must be held.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.clock.Now()
+
+	return nil
+}
+
+// closeFile closes the current file, if any. f.mu must be held.
+func (f *RotatingFile) closeFile() error {
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+
+	return err
+}
+
+// shouldRotate tells whether the file must be rotated before writing n bytes. f.mu must be held.
+func (f *RotatingFile) shouldRotate(n int) bool {
+	if f.clock.Now().Before(f.retryAt) {
+		return false
+	}
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(n) > f.maxSize {
+		return true
+	}
+
+	return f.interval > 0 && f.clock.Now().Sub(f.openedAt) >= f.interval
+}
+
+// rotate moves the file to a backup and opens a new one. f.mu must be held.
+func (f *RotatingFile) rotate() error {
+	if err := f.closeFile(); err != nil {
+		return errors.Join(err, f.open())
+	}
+
+	now := f.clock.Now()
+	backup := f.backupName(now)
+	if err := os.Rename(f.path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		// Keep writing to the current file rather than losing entries, and
+		// wait before trying again.
+		f.retryAt = now.Add(rotateRetryDelay)
+		return errors.Join(err, f.open())
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.cleanups.Go(func() {
+		f.cleanup(backup, now)
+	})
+
+	return nil
+}
+
+// backupName returns a name for a backup made at the given time that is not taken yet.
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := f.nameParts()
+	stamp := t.UTC().Format(backupTimeLayout)
+
+	name := filepath.Join(dir, prefix+stamp+ext)
+	for i := 1; fileExists(name) || fileExists(name+".gz"); i++ {
+		name = filepath.Join(dir, fmt.Sprintf("%s%s.%d%s", prefix, stamp, i, ext))
+	}
+
+	return name
+}
+
+// nameParts returns the directory of the file, and the prefix and extension shared with its backups.
+func (f *RotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(f.path)
+	base := filepath.Base(f.path)
+	ext = filepath.Ext(base)
+
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// cleanup compresses the new backup if required, then removes the backups
+// exceeding the maximum count or age.
+func (f *RotatingFile) cleanup(backup string, now time.Time) {
+	f.cleanupMu.Lock()
+	defer f.cleanupMu.Unlock()
+
+	if f.compress {
+		_ = compressFile(backup)
+	}
+
+	if f.maxBackups <= 0 && f.maxAge <= 0 {
+		return
+	}
+
+	backups, err := f.backups()
+	if err != nil {
+		return
+	}
+
+	for i, b := range backups {
+		tooMany := f.maxBackups > 0 && i >= f.maxBackups
+		tooOld := f.maxAge > 0 && now.Sub(b.time) > f.maxAge
+		if tooMany || tooOld {
+			_ = os.Remove(b.path)
+		}
+	}
+}
+
+// backupFile is a rotated file, and the time it was rotated at.
+type backupFile struct {
+	path string
+	time time.Time
+}
+
+// backups returns the backups of the file, the most recent first.
+func (f *RotatingFile) backups() ([]backupFile, error) {
+	dir, prefix, ext := f.nameParts()
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backupFile
+	for _, de := range dirEntries {
+		name := strings.TrimSuffix(de.Name(), ".gz")
+		if de.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if len(stamp) < len(backupTimeLayout) {
+			continue
+		}
+
+		t, err := time.Parse(backupTimeLayout, stamp[:len(backupTimeLayout)])
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backupFile{path: filepath.Join(dir, de.Name()), time: t})
+	}
+
+	slices.SortStableFunc(backups, func(a, b backupFile) int {
+		return b.time.Compare(a.time)
+	})
+
+	return backups, nil
+}
+
+// compressFile gzips the file at path into path.gz, and removes the original.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(path + ".gz")
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err = zw.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	_ = src.Close()
+	return os.Remove(path)
+}
+
+// fileExists tells whether there is a file at path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package pocketlog
+
+import "os"
+
+// defaultReopenSignals is empty: SIGHUP is only sent on Unix systems.
+var defaultReopenSignals []os.Signal
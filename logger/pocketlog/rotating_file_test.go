@@ -0,0 +1,287 @@
+package pocketlog_test
+
+import (
+	"compress/gzip"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+// manualClock is a clock that only moves when told to.
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newManualClock() *manualClock {
+	return &manualClock{now: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// dirContents returns the names and contents of the files of the directory.
+func dirContents(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	contents := make(map[string]string)
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		contents[e.Name()] = string(data)
+	}
+
+	return contents
+}
+
+func writeString(t *testing.T, w io.Writer, s string) {
+	t.Helper()
+
+	if _, err := io.WriteString(w, s); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestRotatingFile_MaxSize(t *testing.T) {
+	dir := t.TempDir()
+	clock := newManualClock()
+
+	f, err := pocketlog.NewRotatingFile(filepath.Join(dir, "app.log"), pocketlog.FileMaxSize(10), pocketlog.FileClock(clock))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	writeString(t, f, "1234\n")
+	writeString(t, f, "6789\n")
+	clock.Advance(time.Second)
+	writeString(t, f, "abc\n")
+	// A single write larger than the maximum size is not split.
+	clock.Advance(time.Second)
+	writeString(t, f, "a line longer than 10 bytes\n")
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]string{
+		"app-20250102T030406.000.log": "1234\n6789\n",
+		"app-20250102T030407.000.log": "abc\n",
+		"app.log":                     "a line longer than 10 bytes\n",
+	}
+	if got := dirContents(t, dir); !maps.Equal(got, expected) {
+		t.Errorf("invalid files, expected %q, got %q", expected, got)
+	}
+}
+
+func TestRotatingFile_renameFails(t *testing.T) {
+	dir := t.TempDir()
+	clock := newManualClock()
+
+	// The name of the backup is too long for the file system, so the file can't be moved to it.
+	name := strings.Repeat("a", 240) + ".log"
+	f, err := pocketlog.NewRotatingFile(filepath.Join(dir, name), pocketlog.FileMaxSize(10), pocketlog.FileClock(clock))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer f.Close()
+
+	writeString(t, f, "1234567890\n")
+
+	// The entry is written despite the failure, which is reported.
+	if n, err := io.WriteString(f, "second\n"); n != 7 || err == nil {
+		t.Errorf("expected the line to be written along with an error, got %d, %v", n, err)
+	}
+	// Rotating is not tried again until a while later.
+	writeString(t, f, "third\n")
+	clock.Advance(10 * time.Second)
+	if n, err := io.WriteString(f, "fourth\n"); n != 7 || err == nil {
+		t.Errorf("expected the line to be written along with an error, got %d, %v", n, err)
+	}
+
+	expected := map[string]string{name: "1234567890\nsecond\nthird\nfourth\n"}
+	if got := dirContents(t, dir); !maps.Equal(got, expected) {
+		t.Errorf("invalid files, expected %q, got %q", expected, got)
+	}
+}
+
+func TestRotatingFile_Interval(t *testing.T) {
+	dir := t.TempDir()
+	clock := newManualClock()
+
+	f, err := pocketlog.NewRotatingFile(filepath.Join(dir, "app.log"), pocketlog.FileRotateEvery(time.Hour), pocketlog.FileClock(clock))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer f.Close()
+
+	writeString(t, f, "first\n")
+	clock.Advance(59 * time.Minute)
+	writeString(t, f, "second\n")
+	clock.Advance(time.Minute)
+	writeString(t, f, "third\n")
+
+	expected := map[string]string{
+		"app-20250102T040405.000.log": "first\nsecond\n",
+		"app.log":                     "third\n",
+	}
+	if got := dirContents(t, dir); !maps.Equal(got, expected) {
+		t.Errorf("invalid files, expected %q, got %q", expected, got)
+	}
+}
+
+func TestRotatingFile_CompressAndPrune(t *testing.T) {
+	type testCase struct {
+		opts     []pocketlog.FileOption
+		expected []string
+	}
+
+	tt := map[string]testCase{
+		"max backups": {
+			opts:     []pocketlog.FileOption{pocketlog.FileMaxBackups(2)},
+			expected: []string{"app-20250102T050405.000.log", "app-20250102T060405.000.log", "app.log"},
+		},
+		"max age": {
+			opts:     []pocketlog.FileOption{pocketlog.FileMaxAge(90 * time.Minute)},
+			expected: []string{"app-20250102T050405.000.log", "app-20250102T060405.000.log", "app.log"},
+		},
+		"compress": {
+			opts: []pocketlog.FileOption{pocketlog.FileCompress(), pocketlog.FileMaxBackups(3)},
+			expected: []string{
+				"app-20250102T040405.000.log.gz",
+				"app-20250102T050405.000.log.gz",
+				"app-20250102T060405.000.log.gz",
+				"app.log",
+			},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			clock := newManualClock()
+
+			opts := append([]pocketlog.FileOption{pocketlog.FileClock(clock)}, tc.opts...)
+			f, err := pocketlog.NewRotatingFile(filepath.Join(dir, "app.log"), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for range 3 {
+				writeString(t, f, "line\n")
+				clock.Advance(time.Hour)
+				if err := f.Rotate(); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			}
+
+			if err := f.Close(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got := slices.Sorted(maps.Keys(dirContents(t, dir)))
+			if !slices.Equal(got, tc.expected) {
+				t.Errorf("invalid files, expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestRotatingFile_CompressedContents(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := pocketlog.NewRotatingFile(filepath.Join(dir, "app.log"), pocketlog.FileCompress(), pocketlog.FileClock(newManualClock()))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	writeString(t, f, "compressed line\n")
+	if err := f.Rotate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	gz, err := os.Open(filepath.Join(dir, "app-20250102T030405.000.log.gz"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer gz.Close()
+
+	zr, err := gzip.NewReader(gz)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(data) != "compressed line\n" {
+		t.Errorf("invalid contents, got %q", data)
+	}
+}
+
+func TestRotatingFile_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	f, err := pocketlog.NewRotatingFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(f), pocketlog.WithTimeLayout(""))
+	lgr.Infof("before")
+
+	// This is what logrotate does, before notifying the program.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := f.Reopen(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lgr.Infof("after")
+	if err := f.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]string{
+		"app.log.1": "I - before\n",
+		"app.log":   "I - after\n",
+	}
+	if got := dirContents(t, dir); !maps.Equal(got, expected) {
+		t.Errorf("invalid files, expected %q, got %q", expected, got)
+	}
+
+	if _, err := f.Write([]byte("closed")); err == nil {
+		t.Error("expected an error writing to a closed file")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package pocketlog
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultReopenSignals are the signals ReopenOnSignal listens to when none is given.
+var defaultReopenSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,47 @@
+//go:build unix
+
+package pocketlog_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+func TestRotatingFile_ReopenOnSignal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	f, err := pocketlog.NewRotatingFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer f.Close()
+
+	stop := f.ReopenOnSignal(syscall.SIGHUP)
+	defer stop()
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !fileExists(path) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the file to be reopened")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
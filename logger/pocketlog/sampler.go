@@ -0,0 +1,251 @@
+package pocketlog
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sampleKey identifies the entries sampled together.
+type sampleKey struct {
+	level    Level
+	template string
+}
+
+// tokenBucket limits the rate of entries of a level.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// take tells whether a token is available at the given time, consuming it.
+func (b *tokenBucket) take(now time.Time) bool {
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// sampler decides which entries are written, and counts the others.
+// It is shared by a logger and its children.
+type sampler struct {
+	clock Clock
+	// emit writes the summaries of a window once it is over.
+	emit func(summaries []Entry)
+
+	// interval, first and thereafter configure sampling per template; an
+	// interval of zero disables it.
+	interval   time.Duration
+	first      int
+	thereafter int
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[sampleKey]int
+	buckets     map[Level]*tokenBucket
+	suppressed  map[sampleKey]uint64
+	// timer emits the summaries when the window is over, if entries were
+	// suppressed. armedAt is the clock's time when it was armed.
+	timer   *time.Timer
+	armedAt time.Time
+}
+
+// samplingOptions holds the settings of the sampler until it is created.
+type samplingOptions struct {
+	interval   time.Duration
+	first      int
+	thereafter int
+	rateLimits map[Level]rateLimit
+}
+
+// rateLimit is the setting of a token bucket.
+type rateLimit struct {
+	perSecond float64
+	burst     int
+}
+
+// newSampler returns a sampler, or nil if the options enable neither sampling nor rate limiting.
+func newSampler(opts samplingOptions, clock Clock, emit func([]Entry)) *sampler {
+	if opts.interval <= 0 && len(opts.rateLimits) == 0 {
+		return nil
+	}
+
+	s := &sampler{
+		clock:      clock,
+		emit:       emit,
+		interval:   opts.interval,
+		first:      opts.first,
+		thereafter: opts.thereafter,
+		counts:     make(map[sampleKey]int),
+		buckets:    make(map[Level]*tokenBucket),
+		suppressed: make(map[sampleKey]uint64),
+	}
+
+	for level, limit := range opts.rateLimits {
+		s.buckets[level] = &tokenBucket{rate: limit.perSecond, burst: float64(limit.burst), tokens: float64(limit.burst)}
+	}
+
+	return s
+}
+
+// sample tells whether the entry should be written. When a sampling interval
+// is over, it also returns the summaries of the entries suppressed during it.
+// Panic and fatal entries are always written.
+func (s *sampler) sample(e Entry) (bool, []Entry) {
+	if e.Level >= LevelPanic {
+		return true, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+
+	var summaries []Entry
+	if now.Sub(s.windowStart) >= s.period() {
+		summaries = s.nextWindow(now)
+	}
+
+	key := sampleKey{level: e.Level, template: e.template}
+	if !s.keep(key, now) {
+		s.suppressed[key]++
+		if s.timer == nil {
+			s.armTimer(now)
+		}
+		return false, summaries
+	}
+
+	return true, summaries
+}
+
+// defaultSummaryInterval is how often summaries are written when only rate limiting is enabled.
+const defaultSummaryInterval = time.Minute
+
+// period returns the duration of the windows entries are sampled and summarized over.
+func (s *sampler) period() time.Duration {
+	if s.interval > 0 {
+		return s.interval
+	}
+
+	return defaultSummaryInterval
+}
+
+// keep applies sampling, then rate limiting, to an entry. s.mu must be held.
+func (s *sampler) keep(key sampleKey, now time.Time) bool {
+	if s.interval > 0 {
+		s.counts[key]++
+		n := s.counts[key]
+		if n > s.first && (s.thereafter <= 0 || (n-s.first)%s.thereafter != 0) {
+			return false
+		}
+	}
+
+	if b, ok := s.buckets[key.level]; ok && !b.take(now) {
+		return false
+	}
+
+	return true
+}
+
+// nextWindow starts a new window at the given time, and returns the summaries
+// of the previous one. s.mu must be held.
+func (s *sampler) nextWindow(now time.Time) []Entry {
+	s.stopTimer()
+	s.windowStart = now
+	clear(s.counts)
+
+	return s.summaries()
+}
+
+// armTimer schedules windowOver at the end of the window. s.mu must be held.
+func (s *sampler) armTimer(now time.Time) {
+	s.armedAt = now
+	s.timer = time.AfterFunc(s.windowStart.Add(s.period()).Sub(now), s.windowOver)
+}
+
+// windowOver emits the summaries of the window once it is over, so that they
+// are written even if no entry is logged afterwards. If the clock says the
+// window is not over yet, it waits for the rest of it, unless the clock did
+// not move at all, as a fixed clock in tests: the summaries are then left to
+// the next entry, Flush or Close.
+func (s *sampler) windowOver() {
+	s.mu.Lock()
+	now := s.clock.Now()
+	if now.Before(s.windowStart.Add(s.period())) {
+		s.timer = nil
+		if !now.Equal(s.armedAt) {
+			s.armTimer(now)
+		}
+		s.mu.Unlock()
+		return
+	}
+
+	s.timer = nil
+	summaries := s.nextWindow(now)
+	s.mu.Unlock()
+
+	s.emit(summaries)
+}
+
+// stopTimer cancels the emission of the summaries at the end of the window. s.mu must be held.
+func (s *sampler) stopTimer() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+// drain returns the summaries of the entries suppressed so far.
+func (s *sampler) drain() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopTimer()
+
+	return s.summaries()
+}
+
+// summaries returns a warning entry per template with suppressed entries, and
+// resets their counts. s.mu must be held.
+func (s *sampler) summaries() []Entry {
+	if len(s.suppressed) == 0 {
+		return nil
+	}
+
+	keys := make([]sampleKey, 0, len(s.suppressed))
+	for key := range s.suppressed {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b sampleKey) int {
+		if a.level != b.level {
+			return int(a.level) - int(b.level)
+		}
+		return strings.Compare(a.template, b.template)
+	})
+
+	summaries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		summaries = append(summaries, Entry{
+			Level:   LevelWarn,
+			Message: "suppressed log entries",
+			Fields: []Field{
+				String("template", key.template),
+				String("level", key.level.Name()),
+				Uint64("suppressed", s.suppressed[key]),
+			},
+		})
+	}
+	clear(s.suppressed)
+
+	return summaries
+}
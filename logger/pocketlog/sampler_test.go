@@ -0,0 +1,167 @@
+package pocketlog_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+func TestLogger_WithSampling(t *testing.T) {
+	tw := &testWriter{}
+	clock := newManualClock()
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(clock),
+		pocketlog.WithTimeLayout(time.TimeOnly),
+		pocketlog.WithSampling(time.Second, 2, 3),
+	)
+
+	for i := 1; i <= 9; i++ {
+		lgr.Errorf("retry %d", i)
+	}
+	lgr.Info("other template")
+
+	clock.Advance(time.Second)
+	lgr.Errorf("retry %d", 10)
+
+	expected := "03:04:05 E - retry 1\n" +
+		"03:04:05 E - retry 2\n" +
+		"03:04:05 E - retry 5\n" +
+		"03:04:05 E - retry 8\n" +
+		"03:04:05 I - other template\n" +
+		`03:04:06 W - suppressed log entries template="retry %d" level=error suppressed=5` + "\n" +
+		"03:04:06 E - retry 10\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_WithRateLimit(t *testing.T) {
+	tw := &testWriter{}
+	clock := newManualClock()
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(clock),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithRateLimit(pocketlog.LevelError, 2, 3),
+	)
+
+	for range 5 {
+		lgr.Errorf(errorMessage)
+	}
+	// Other levels are not limited.
+	lgr.Infof(infoMessage)
+
+	// Half a second refills one token.
+	clock.Advance(500 * time.Millisecond)
+	lgr.Errorf(errorMessage)
+	lgr.Errorf(errorMessage)
+
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "E - " + errorMessage + "\n" +
+		"E - " + errorMessage + "\n" +
+		"E - " + errorMessage + "\n" +
+		"I - " + infoMessage + "\n" +
+		"E - " + errorMessage + "\n" +
+		`W - suppressed log entries template="` + errorMessage + `" level=error suppressed=3` + "\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_SamplingSparesFatal(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithSampling(time.Hour, 0, 0),
+		pocketlog.WithExitFunc(func(int) {}),
+	)
+
+	lgr.Infof(infoMessage)
+	lgr.Fatalf("cannot continue")
+
+	expected := "F - cannot continue\n" +
+		`W - suppressed log entries template="` + infoMessage + `" level=info suppressed=1` + "\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+// countingClock is a fixed clock counting how many times it is read.
+type countingClock struct {
+	reads atomic.Int64
+}
+
+func (c *countingClock) Now() time.Time {
+	c.reads.Add(1)
+	return fixedClock.Now()
+}
+
+func TestLogger_WithSampling_fixedClock(t *testing.T) {
+	clock := &countingClock{}
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(&testWriter{}),
+		pocketlog.WithClock(clock),
+		pocketlog.WithSampling(time.Millisecond, 1, 0),
+	)
+
+	for range 3 {
+		lgr.Errorf(errorMessage)
+	}
+
+	// The window never ends with a clock that doesn't move: the timer gives up
+	// instead of reading the clock again and again.
+	time.Sleep(50 * time.Millisecond)
+	reads := clock.reads.Load()
+	time.Sleep(50 * time.Millisecond)
+	if got := clock.reads.Load(); got != reads {
+		t.Errorf("expected the timer to stop, the clock was read %d more times", got-reads)
+	}
+
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// No timer is left after Close.
+	reads = clock.reads.Load()
+	time.Sleep(10 * time.Millisecond)
+	if got := clock.reads.Load(); got != reads {
+		t.Errorf("expected no timer after Close, the clock was read %d more times", got-reads)
+	}
+}
+
+func TestLogger_WithSampling_periodicSummary(t *testing.T) {
+	w := make(notifyingWriter, 10)
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(w),
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithSampling(20*time.Millisecond, 1, 0),
+	)
+
+	for range 3 {
+		lgr.Errorf(errorMessage)
+	}
+
+	// The summary is written once the window is over, without waiting for another entry.
+	expected := []string{
+		"E - " + errorMessage + "\n",
+		`W - suppressed log entries template="` + errorMessage + `" level=error suppressed=2` + "\n",
+	}
+	for _, line := range expected {
+		select {
+		case got := <-w:
+			if got != line {
+				t.Errorf("invalid line, expected %q, got %q", line, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %q", line)
+		}
+	}
+}
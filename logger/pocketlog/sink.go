@@ -0,0 +1,217 @@
+package pocketlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// Sink receives the entries of a logger.
+// WriteEntry may be called concurrently, and must not keep the entry's fields
+// once it returns.
+type Sink interface {
+	WriteEntry(e Entry) error
+}
+
+// WriterSink is a Sink formatting entries to an io.Writer. It only writes
+// entries at or above its level, which makes it possible for several sinks of
+// a logger to have different thresholds. Entries are written with a single
+// call to Write, serialized across goroutines, unless the output writes only
+// part of it, in which case the rest is written by further calls.
+type WriterSink struct {
+	// mu guards output.
+	mu        sync.Mutex
+	output    io.Writer
+	formatter Formatter
+	level     Level
+	async     *asyncQueue
+
+	// diag handles the failures of background writes. It is set by the
+	// logger the sink is given to.
+	diag atomic.Pointer[diagnostics]
+}
+
+// SinkOption defines a functional option to a WriterSink.
+type SinkOption func(*WriterSink, *asyncOptions)
+
+// SinkLevel returns a configuration function that sets the minimum level of the
+// entries a sink writes. The logger's threshold still applies first.
+func SinkLevel(level Level) SinkOption {
+	return func(s *WriterSink, _ *asyncOptions) {
+		s.level = level
+	}
+}
+
+// SinkFormatter returns a configuration function that sets the formatter of a sink.
+// It defaults to a TextFormatter.
+func SinkFormatter(formatter Formatter) SinkOption {
+	return func(s *WriterSink, _ *asyncOptions) {
+		s.formatter = formatter
+	}
+}
+
+// SinkAsync returns a configuration function that makes a sink write entries
+// from a background goroutine, like WithAsync does for a logger, so that a
+// slow output doesn't hold up the other sinks.
+func SinkAsync(bufferSize int, policy OverflowPolicy) SinkOption {
+	return func(_ *WriterSink, opts *asyncOptions) {
+		opts.enabled = true
+		opts.size = bufferSize
+		opts.policy = policy
+	}
+}
+
+// NewSink returns a sink formatting entries to the output.
+func NewSink(output io.Writer, opts ...SinkOption) *WriterSink {
+	s := &WriterSink{output: output, formatter: TextFormatter{}, level: LevelTrace}
+
+	asyncOpts := asyncOptions{dropLevel: LevelError}
+	for _, opt := range opts {
+		opt(s, &asyncOpts)
+	}
+
+	if asyncOpts.enabled {
+		s.async = newAsyncQueue(asyncOpts.size, asyncOpts.policy, asyncOpts.dropLevel, func(e Entry) {
+			if err := s.write(e); err != nil {
+				if d := s.diag.Load(); d != nil {
+					d.writeFailed(e, err)
+				}
+			}
+		})
+	}
+
+	return s
+}
+
+// WriteEntry implements Sink.
+func (s *WriterSink) WriteEntry(e Entry) error {
+	if e.Level < s.level {
+		return nil
+	}
+
+	if s.async != nil && s.async.enqueue(e) {
+		return nil
+	}
+
+	return s.write(e)
+}
+
+// write formats the entry and prints it to the output, writing the rest again after a short write.
+func (s *WriterSink) write(e Entry) error {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer putBuffer(buf)
+
+	if err := s.formatter.Format(buf, e); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeFull(s.output, buf.Bytes())
+}
+
+// Flush waits until the entries of an asynchronous sink have been written, or the context is done.
+func (s *WriterSink) Flush(ctx context.Context) error {
+	if s.async == nil {
+		return nil
+	}
+
+	return s.async.flush(ctx)
+}
+
+// Close writes the pending entries of an asynchronous sink and stops its goroutine.
+func (s *WriterSink) Close(ctx context.Context) error {
+	if s.async == nil {
+		return nil
+	}
+
+	return s.async.close(ctx)
+}
+
+// reportFailures makes the sink hand the failures of background writes to the diagnostics.
+func (s *WriterSink) reportFailures(d *diagnostics) {
+	s.diag.Store(d)
+}
+
+// Dropped returns the number of entries an asynchronous sink dropped because its buffer was full.
+func (s *WriterSink) Dropped() uint64 {
+	if s.async == nil {
+		return 0
+	}
+
+	return s.async.dropped.Load()
+}
+
+// slogSink is a Sink emitting entries through a slog.Handler.
+type slogSink struct {
+	handler slog.Handler
+}
+
+// NewSlogSink returns a sink emitting entries through the slog handler.
+func NewSlogSink(handler slog.Handler) Sink {
+	return slogSink{handler: handler}
+}
+
+// WriteEntry implements Sink.
+func (s slogSink) WriteEntry(e Entry) error {
+	return writeSlog(s.handler, e)
+}
+
+// flusher and closer are implemented by sinks that hold entries back, such as
+// asynchronous ones, and failureReporter by those writing them in the background.
+type (
+	flusher interface {
+		Flush(ctx context.Context) error
+	}
+	closer interface {
+		Close(ctx context.Context) error
+	}
+	failureReporter interface {
+		reportFailures(d *diagnostics)
+	}
+)
+
+// writeSinks hands the entry over to every sink. A sink failing, or
+// panicking, doesn't prevent the others from receiving the entry.
+func writeSinks(sinks []Sink, e Entry) error {
+	var errs []error
+	for _, s := range sinks {
+		if err := writeSink(s, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// writeSink hands the entry over to the sink, turning a panic into an error.
+func writeSink(s Sink, e Entry) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("sink panicked: %v", r)
+		}
+	}()
+
+	return s.WriteEntry(e)
+}
+
+// bufferPool recycles the buffers entries are formatted into.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// putBuffer returns a buffer to the pool, unless it grew too large to be worth keeping.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > 64<<10 {
+		return
+	}
+
+	buf.Reset()
+	bufferPool.Put(buf)
+}
@@ -0,0 +1,111 @@
+package pocketlog_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+// panickingSink panics on every entry.
+type panickingSink struct{}
+
+func (panickingSink) WriteEntry(pocketlog.Entry) error {
+	panic("broken sink")
+}
+
+func TestLogger_WithSinks(t *testing.T) {
+	stderr := &testWriter{}
+	file := &testWriter{}
+	memory := &testWriter{}
+
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithSinks(
+			pocketlog.NewSink(stderr, pocketlog.SinkLevel(pocketlog.LevelError)),
+			pocketlog.NewSink(file, pocketlog.SinkLevel(pocketlog.LevelError), pocketlog.SinkFormatter(pocketlog.JSONFormatter{})),
+			pocketlog.NewSink(memory),
+		),
+	)
+
+	lgr.Debugf(debugMessage)
+	lgr.Infof(infoMessage)
+	lgr.Errorf(errorMessage)
+
+	if expected := "E - " + errorMessage + "\n"; stderr.contents != expected {
+		t.Errorf("invalid stderr contents, expected %q, got %q", expected, stderr.contents)
+	}
+	if expected := `{"level":"error","msg":"` + errorMessage + `"}` + "\n"; file.contents != expected {
+		t.Errorf("invalid file contents, expected %q, got %q", expected, file.contents)
+	}
+	if expected := "D - " + debugMessage + "\nI - " + infoMessage + "\nE - " + errorMessage + "\n"; memory.contents != expected {
+		t.Errorf("invalid memory contents, expected %q, got %q", expected, memory.contents)
+	}
+}
+
+func TestLogger_FailingSinks(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithSinks(
+			pocketlog.NewSink(failingWriter{}),
+			panickingSink{},
+			pocketlog.NewSink(tw),
+		),
+	)
+
+	lgr.Infof(infoMessage)
+	lgr.Errorf(errorMessage)
+
+	if expected := "I - " + infoMessage + "\nE - " + errorMessage + "\n"; tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_BlockedAsyncSink(t *testing.T) {
+	blocked := newGateWriter()
+	blockedSink := pocketlog.NewSink(blocked, pocketlog.SinkAsync(1, pocketlog.OverflowDropNewest))
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithTimeLayout(""),
+		pocketlog.WithSinks(blockedSink, pocketlog.NewSink(tw)),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 10 {
+			lgr.Infof(infoMessage)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the blocked sink not to hold up the logger")
+	}
+
+	if got := strings.Count(tw.contents, "\n"); got != 10 {
+		t.Errorf("expected 10 lines in the healthy sink, got %d", got)
+	}
+
+	close(blocked.gate)
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	written, dropped := uint64(len(blocked.written())), blockedSink.Dropped()
+	if written+dropped != 10 || dropped < 8 {
+		t.Errorf("expected at most 2 lines written and the others dropped, got %d and %d", written, dropped)
+	}
+}
@@ -0,0 +1,209 @@
+package pocketlog
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// SlogHandler is a slog.Handler that routes records into a Logger.
+type SlogHandler struct {
+	lgr *Logger
+	// groups are the groups opened by WithGroup.
+	groups []string
+	// fields holds, for each depth of groups, the attributes added by WithAttrs at that depth.
+	fields [][]Field
+}
+
+// NewSlogHandler returns a slog.Handler writing to the given logger.
+// Records are logged with the logger's fields, name and threshold.
+func NewSlogHandler(lgr *Logger) *SlogHandler {
+	return &SlogHandler{lgr: lgr, fields: make([][]Field, 1)}
+}
+
+// Enabled implements slog.Handler, checking the level against the logger's threshold.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.lgr.enabled(levelFromSlog(level))
+}
+
+// Handle implements slog.Handler.
+// Fields extracted from the context come after those added by WithAttrs outside any group.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make([]Field, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, a)
+		return true
+	})
+
+	// Nest the fields in the open groups, innermost first, leaving empty groups out.
+	for depth := len(h.groups); depth > 0; depth-- {
+		fields = append(slices.Clip(h.fields[depth]), fields...)
+		if len(fields) > 0 {
+			fields = []Field{Group(h.groups[depth-1], fields...)}
+		}
+	}
+	fields = append(slices.Clip(h.fields[0]), h.lgr.contextFields(ctx, fields)...)
+
+	entry := h.lgr.newEntry(levelFromSlog(r.Level), h.lgr.stamp(r.Time), r.Message, fields)
+	if h.lgr.addCaller && r.PC != 0 {
+		entry.Caller = callerFromPC(r.PC)
+	}
+
+	h.lgr.dispatch(entry)
+
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []Field
+	for _, a := range attrs {
+		fields = appendAttr(fields, a)
+	}
+
+	if len(fields) == 0 {
+		return h
+	}
+
+	child := *h
+	child.fields = slices.Clone(h.fields)
+	depth := len(h.groups)
+	child.fields[depth] = append(slices.Clip(h.fields[depth]), fields...)
+
+	return &child
+}
+
+// WithGroup implements slog.Handler.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	child := *h
+	child.groups = append(slices.Clip(h.groups), name)
+	child.fields = append(slices.Clip(h.fields), nil)
+
+	return &child
+}
+
+// appendAttr appends the attribute to fields, resolving its value.
+// Empty attributes and groups are left out, and groups without a key are inlined.
+func appendAttr(fields []Field, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		return append(fields, Field{Key: a.Key, Value: valueFromSlog(a.Value)})
+	}
+
+	var group []Field
+	for _, ga := range a.Value.Group() {
+		group = appendAttr(group, ga)
+	}
+
+	switch {
+	case len(group) == 0:
+		return fields
+	case a.Key == "":
+		return append(fields, group...)
+	default:
+		return append(fields, Group(a.Key, group...))
+	}
+}
+
+// valueFromSlog returns the Go value held by a resolved, non-group slog.Value.
+func valueFromSlog(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return v.Duration()
+	case slog.KindTime:
+		return v.Time()
+	default:
+		return v.Any()
+	}
+}
+
+// levelFromSlog maps a slog level onto the closest pocketlog level at or below it.
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return LevelTrace
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+// levelToSlog maps a pocketlog level onto a slog level.
+func levelToSlog(level Level) slog.Level {
+	switch level {
+	case LevelTrace:
+		return slog.LevelDebug - 4
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	case LevelPanic:
+		return slog.LevelError + 4
+	default:
+		return slog.LevelError + 8
+	}
+}
+
+// writeSlog emits the entry through the handler, if it is enabled for the entry's level.
+func writeSlog(h slog.Handler, e Entry) error {
+	ctx := context.Background()
+	level := levelToSlog(e.Level)
+	if !h.Enabled(ctx, level) {
+		return nil
+	}
+
+	var pc uintptr
+	if e.Caller != nil {
+		pc = e.Caller.pc
+	}
+
+	r := slog.NewRecord(e.Time, level, e.Message, pc)
+	if e.LoggerName != "" {
+		r.AddAttrs(slog.String("logger", e.LoggerName))
+	}
+	r.AddAttrs(attrsFromFields(e.Fields)...)
+
+	return h.Handle(ctx, r)
+}
+
+// attrsFromFields converts fields, groups included, into slog attributes.
+func attrsFromFields(fields []Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		if group, ok := f.Value.([]Field); ok {
+			attrs = append(attrs, slog.Attr{Key: f.Key, Value: slog.GroupValue(attrsFromFields(group)...)})
+			continue
+		}
+
+		attrs = append(attrs, slog.Any(f.Key, f.Value))
+	}
+
+	return attrs
+}
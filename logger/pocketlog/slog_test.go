@@ -0,0 +1,111 @@
+package pocketlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+func TestSlogHandler_Conformance(t *testing.T) {
+	var buf bytes.Buffer
+
+	newHandler := func(*testing.T) slog.Handler {
+		buf.Reset()
+		lgr := pocketlog.New(pocketlog.LevelTrace, pocketlog.WithOutput(&buf), pocketlog.WithJSON())
+		return pocketlog.NewSlogHandler(lgr)
+	}
+
+	result := func(t *testing.T) map[string]any {
+		var m map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+			t.Fatalf("invalid JSON %q: %s", buf.String(), err)
+		}
+		return m
+	}
+
+	slogtest.Run(t, newHandler, result)
+}
+
+func TestSlogHandler(t *testing.T) {
+	tw := &testWriter{}
+	registry, err := pocketlog.ParseLevelSpec("db=warn")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""), pocketlog.WithLevelRegistry(registry))
+
+	logger := slog.New(pocketlog.NewSlogHandler(lgr.With(pocketlog.String("service", "api"))))
+	logger.Debug("starting", "port", 8080)
+	logger.WithGroup("req").With("id", 42).Info("served", slog.Group("resp", "status", 200))
+	logger.Log(t.Context(), slog.LevelDebug-4, "too verbose")
+	logger.Warn("slow")
+
+	dbLogger := slog.New(pocketlog.NewSlogHandler(lgr.Named("db")))
+	dbLogger.Info("filtered out")
+	dbLogger.Error("failed")
+
+	expected := "D - starting service=api port=8080\n" +
+		"I - served service=api req.id=42 req.resp.status=200\n" +
+		"W - slow service=api\n" +
+		"E - [db] failed\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_WithSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug - 4})
+	lgr := pocketlog.New(pocketlog.LevelTrace, pocketlog.WithSlogHandler(handler), pocketlog.WithTimeLayout(""))
+
+	lgr.Named("db").With(pocketlog.Group("conn", pocketlog.Int("id", 7))).Trace("query", pocketlog.String("table", "users"))
+	lgr.Warnf("retry %d", 2)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		`{"level":"DEBUG-4","msg":"query","logger":"db","conn":{"id":7},"table":"users"}`,
+		`{"level":"WARN","msg":"retry 2"}`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("invalid contents, expected %q, got %q", expected, lines)
+	}
+}
+
+func TestLogger_Group(t *testing.T) {
+	type testCase struct {
+		formatter pocketlog.Formatter
+		expected  string
+	}
+
+	tt := map[string]testCase{
+		"text": {
+			formatter: pocketlog.TextFormatter{},
+			expected:  "I - " + infoMessage + " req.method=GET req.headers.host=example.com\n",
+		},
+		"json": {
+			formatter: pocketlog.JSONFormatter{},
+			expected:  `{"level":"info","msg":"` + infoMessage + `","req":{"method":"GET","headers":{"host":"example.com"}}}` + "\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""), pocketlog.WithFormatter(tc.formatter))
+
+			lgr.Info(infoMessage, pocketlog.Group("req",
+				pocketlog.String("method", "GET"),
+				pocketlog.Group("headers", pocketlog.String("host", "example.com")),
+			))
+
+			if tw.contents != tc.expected {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package pocketlog
+
+import (
+	"io"
+	"log"
+	"strings"
+)
+
+// StdOption configures the bridge from the standard library log package.
+type StdOption func(*stdWriter)
+
+// ParseLevelPrefix returns a StdOption that reads the level of each line from
+// a leading prefix such as "[WARN] " or "error: ", removing it from the message.
+// Lines without a recognized prefix are logged at the bridge's level.
+func ParseLevelPrefix() StdOption {
+	return func(w *stdWriter) {
+		w.parsePrefix = true
+	}
+}
+
+// stdWriter forwards each line written to it into a Logger.
+type stdWriter struct {
+	lgr         *Logger
+	level       Level
+	parsePrefix bool
+}
+
+// NewStdWriter returns an io.Writer logging each line it receives to the
+// logger, at the given level, without its trailing newline.
+func NewStdWriter(lgr *Logger, level Level, opts ...StdOption) io.Writer {
+	w := &stdWriter{lgr: lgr, level: level}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// NewStdLogger returns a *log.Logger, such as http.Server.ErrorLog expects,
+// logging each line to the logger at the given level.
+func NewStdLogger(lgr *Logger, level Level, opts ...StdOption) *log.Logger {
+	return log.New(NewStdWriter(lgr, level, opts...), "", 0)
+}
+
+// RedirectStdLog sends the output of the global log package to the logger, at
+// the given level. The returned function restores the previous output, flags
+// and prefix.
+func RedirectStdLog(lgr *Logger, level Level, opts ...StdOption) (restore func()) {
+	output, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+
+	log.SetOutput(NewStdWriter(lgr, level, opts...))
+	log.SetFlags(0)
+	log.SetPrefix("")
+
+	return func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}
+}
+
+// stdLogDepth is the number of frames between stdWriter.Write and the call to
+// a printing function of the log package, such as log.Printf.
+const stdLogDepth = 3
+
+// Write implements io.Writer.
+func (w *stdWriter) Write(p []byte) (int, error) {
+	var caller *Caller
+	if w.lgr.addCaller {
+		caller = callerAt(stdLogDepth + w.lgr.callerSkip)
+	}
+
+	text := strings.TrimRight(string(p), "\r\n")
+	for line := range strings.SplitSeq(text, "\n") {
+		level, message := w.level, strings.TrimSuffix(line, "\r")
+		if w.parsePrefix {
+			if prefixed, rest, ok := parseLevelPrefix(message); ok {
+				level, message = prefixed, rest
+			}
+		}
+
+		if !w.lgr.enabled(level) {
+			continue
+		}
+
+		entry := w.lgr.newEntry(level, w.lgr.now(), message, nil)
+		entry.Caller = caller
+		w.lgr.dispatch(entry)
+	}
+
+	return len(p), nil
+}
+
+// parseLevelPrefix reads a level name in brackets or followed by a colon at
+// the start of the line, such as "[WARN] " or "error: ".
+func parseLevelPrefix(line string) (Level, string, bool) {
+	token, rest, found := strings.Cut(line, " ")
+	if !found {
+		token, rest = line, ""
+	}
+
+	var name string
+	switch {
+	case strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]"):
+		name = token[1 : len(token)-1]
+	case strings.HasSuffix(token, ":"):
+		name = token[:len(token)-1]
+	default:
+		return 0, "", false
+	}
+
+	// Letters and numbers would be too easily mistaken for a level.
+	level, err := ParseLevel(name)
+	if err != nil || len(name) < 4 {
+		return 0, "", false
+	}
+
+	return level, strings.TrimLeft(rest, " "), true
+}
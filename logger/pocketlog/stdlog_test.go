@@ -0,0 +1,76 @@
+package pocketlog_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+func TestNewStdLogger(t *testing.T) {
+	type testCase struct {
+		opts     []pocketlog.StdOption
+		log      func(stdLogger *log.Logger)
+		expected string
+	}
+
+	tt := map[string]testCase{
+		"single line": {
+			log:      func(stdLogger *log.Logger) { stdLogger.Printf("http: TLS handshake error from %s", "10.0.0.1") },
+			expected: "E - http: TLS handshake error from 10.0.0.1\n",
+		},
+		"several lines": {
+			log:      func(stdLogger *log.Logger) { stdLogger.Print("first\nsecond\r\n") },
+			expected: "E - first\nE - second\n",
+		},
+		"prefix ignored by default": {
+			log:      func(stdLogger *log.Logger) { stdLogger.Print("[WARN] disk almost full") },
+			expected: "E - [WARN] disk almost full\n",
+		},
+		"parsed prefixes": {
+			opts: []pocketlog.StdOption{pocketlog.ParseLevelPrefix()},
+			log: func(stdLogger *log.Logger) {
+				stdLogger.Print("[WARN] disk almost full")
+				stdLogger.Print("info: listening")
+				stdLogger.Print("debug: filtered out")
+				stdLogger.Print("error connecting")
+				stdLogger.Print("I: not a level")
+			},
+			expected: "W - disk almost full\nI - listening\nE - error connecting\nE - I: not a level\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""))
+
+			tc.log(pocketlog.NewStdLogger(lgr, pocketlog.LevelError, tc.opts...))
+
+			if tw.contents != tc.expected {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithTimeLayout(""), pocketlog.WithCaller())
+
+	restore := pocketlog.RedirectStdLog(lgr, pocketlog.LevelInfo)
+	log.Printf("from the standard library")
+	line := currentLine() - 1
+	restore()
+
+	expected := fmt.Sprintf("I - from the standard library caller=pocketlog/stdlog_test.go:%d\n", line)
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+
+	if log.Writer() != os.Stderr || log.Flags() != log.LstdFlags {
+		t.Error("expected the log package to be restored")
+	}
+}
@@ -0,0 +1,509 @@
+package pocketlog
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFormat is the message format of a SyslogSink.
+type SyslogFormat byte
+
+const (
+	// SyslogRFC5424 formats messages as per RFC 5424, fields becoming structured data.
+	SyslogRFC5424 SyslogFormat = iota
+	// SyslogRFC3164 formats messages as per RFC 3164, the BSD syslog protocol,
+	// fields following the message as key=value pairs.
+	SyslogRFC3164
+)
+
+// Facility is the syslog facility of messages, which tells the kind of program sending them.
+type Facility byte
+
+// Syslog facilities, as listed by RFC 5424.
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	FacilityNTP
+	FacilityAudit
+	FacilityAlert
+	FacilityClock
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// syslogSeverity maps a level onto a syslog severity, from 0 (emergency) to 7 (debug).
+func syslogSeverity(level Level) int {
+	switch level {
+	case LevelTrace, LevelDebug:
+		return 7
+	case LevelInfo:
+		return 6
+	case LevelWarn:
+		return 4
+	case LevelError:
+		return 3
+	case LevelPanic:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// syslogDialTimeout bounds the time spent connecting to a syslog server.
+const syslogDialTimeout = 10 * time.Second
+
+// syslogWriteTimeout is the default time a SyslogSink waits for a message to
+// be written to the connection.
+const syslogWriteTimeout = 10 * time.Second
+
+// syslogMinBackoff and syslogMaxBackoff bound the delay between attempts at
+// reconnecting to a syslog server, which doubles after each failure.
+const (
+	syslogMinBackoff = 100 * time.Millisecond
+	syslogMaxBackoff = 10 * time.Second
+)
+
+// errSyslogUnavailable is returned while a SyslogSink waits to reconnect.
+var errSyslogUnavailable = errors.New("syslog server unavailable, waiting to reconnect")
+
+// SyslogSink is a Sink sending entries to a syslog server, over UDP, TCP or a
+// local unix socket. Over stream connections, messages are framed with octet
+// counting, as per RFC 6587. When a stream connection breaks, the sink
+// reconnects, waiting longer after each failure; in the meantime, writes fail
+// without waiting. A write that times out, because the server stopped reading,
+// is handled like a broken connection. Levels are mapped onto severities: trace and debug entries are
+// sent as debug, info as informational, warn as warning, error as error,
+// panic as critical and fatal as alert.
+type SyslogSink struct {
+	network  string
+	address  string
+	format   SyslogFormat
+	facility Facility
+	hostname string
+	appName  string
+	sdID     string
+	level    Level
+	timeout  time.Duration
+
+	// mu guards the fields below. It is not held while dialing.
+	mu       sync.Mutex
+	conn     net.Conn
+	closed   bool
+	dialing  bool
+	failures int
+	retryAt  time.Time
+}
+
+// SyslogOption defines a functional option to a SyslogSink.
+type SyslogOption func(*SyslogSink)
+
+// SyslogMessageFormat returns a configuration function that sets the format of
+// messages. It defaults to SyslogRFC5424.
+func SyslogMessageFormat(format SyslogFormat) SyslogOption {
+	return func(s *SyslogSink) {
+		s.format = format
+	}
+}
+
+// SyslogFacility returns a configuration function that sets the facility of
+// messages. It defaults to FacilityUser.
+func SyslogFacility(facility Facility) SyslogOption {
+	return func(s *SyslogSink) {
+		s.facility = facility
+	}
+}
+
+// SyslogHostname returns a configuration function that sets the host name sent
+// with messages. It defaults to the name reported by the kernel.
+func SyslogHostname(hostname string) SyslogOption {
+	return func(s *SyslogSink) {
+		s.hostname = hostname
+	}
+}
+
+// SyslogAppName returns a configuration function that sets the application
+// name, or tag, sent with messages. It defaults to the name of the program.
+func SyslogAppName(name string) SyslogOption {
+	return func(s *SyslogSink) {
+		s.appName = name
+	}
+}
+
+// SyslogStructuredDataID returns a configuration function that sets the ID of
+// the RFC 5424 structured data element holding the fields. It defaults to
+// "fields@32473", 32473 being the enterprise number reserved for documentation.
+func SyslogStructuredDataID(id string) SyslogOption {
+	return func(s *SyslogSink) {
+		s.sdID = id
+	}
+}
+
+// SyslogLevel returns a configuration function that sets the minimum level of
+// the entries a sink sends. The logger's threshold still applies first.
+func SyslogLevel(level Level) SyslogOption {
+	return func(s *SyslogSink) {
+		s.level = level
+	}
+}
+
+// SyslogWriteTimeout returns a configuration function that sets the time a
+// sink waits for a message to be written. It defaults to 10s.
+func SyslogWriteTimeout(timeout time.Duration) SyslogOption {
+	return func(s *SyslogSink) {
+		s.timeout = timeout
+	}
+}
+
+// NewSyslogSink connects to the syslog server listening at the address on the
+// network, which is "udp", "tcp" or "unix", or one of their variants such as
+// "tcp6" or "unixgram". A "unix" socket is first tried as a datagram socket,
+// such as /dev/log on most Linux systems, then as a stream socket.
+func NewSyslogSink(network, address string, opts ...SyslogOption) (*SyslogSink, error) {
+	s := &SyslogSink{
+		network:  network,
+		address:  address,
+		facility: FacilityUser,
+		appName:  filepath.Base(os.Args[0]),
+		sdID:     "fields@32473",
+		level:    LevelTrace,
+		timeout:  syslogWriteTimeout,
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		s.hostname = hostname
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	conn, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+	s.conn = conn
+
+	return s, nil
+}
+
+// dial connects to the syslog server.
+func (s *SyslogSink) dial() (net.Conn, error) {
+	if s.network != "unix" {
+		return net.DialTimeout(s.network, s.address, syslogDialTimeout)
+	}
+
+	conn, err := net.DialTimeout("unixgram", s.address, syslogDialTimeout)
+	if err == nil {
+		return conn, nil
+	}
+
+	return net.DialTimeout("unix", s.address, syslogDialTimeout)
+}
+
+// WriteEntry implements Sink. If a stream connection is broken, it reconnects
+// and sends the message again, once.
+func (s *SyslogSink) WriteEntry(e Entry) error {
+	if e.Level < s.level {
+		return nil
+	}
+
+	var msg []byte
+	if s.format == SyslogRFC3164 {
+		msg = s.appendRFC3164(nil, e)
+	} else {
+		msg = s.appendRFC5424(nil, e)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return net.ErrClosed
+	}
+
+	if s.conn == nil {
+		if err := s.reconnect(); err != nil {
+			return err
+		}
+	}
+
+	err := s.send(msg)
+	if err == nil || !isStream(s.conn) {
+		return err
+	}
+
+	_ = s.conn.Close()
+	s.conn = nil
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		// Sending again would likely time out as well.
+		s.backoff()
+		return err
+	}
+	if dialErr := s.reconnect(); dialErr != nil {
+		return errors.Join(err, dialErr)
+	}
+
+	return s.send(msg)
+}
+
+// reconnect dials the syslog server again. It fails fast while another
+// goroutine dials, or until the backoff delay following a failure is over.
+// s.mu must be held; it is released while dialing.
+func (s *SyslogSink) reconnect() error {
+	if s.dialing || time.Now().Before(s.retryAt) {
+		return errSyslogUnavailable
+	}
+
+	s.dialing = true
+	s.mu.Unlock()
+	conn, err := s.dial()
+	s.mu.Lock()
+	s.dialing = false
+
+	if err != nil {
+		s.backoff()
+		return err
+	}
+
+	if s.closed {
+		_ = conn.Close()
+		return net.ErrClosed
+	}
+
+	s.conn = conn
+	s.failures = 0
+
+	return nil
+}
+
+// backoff delays the next attempt at reconnecting, after a failure. s.mu must be held.
+func (s *SyslogSink) backoff() {
+	s.retryAt = time.Now().Add(min(syslogMinBackoff<<min(s.failures, 16), syslogMaxBackoff))
+	s.failures++
+}
+
+// send frames the message according to the connection's transport and writes it. s.mu must be held.
+func (s *SyslogSink) send(msg []byte) error {
+	if isStream(s.conn) {
+		msg = append(strconv.AppendInt(nil, int64(len(msg)), 10), append([]byte{' '}, msg...)...)
+	}
+
+	if s.timeout > 0 {
+		_ = s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	}
+
+	_, err := s.conn.Write(msg)
+	return err
+}
+
+// isStream tells whether the connection is stream-oriented, rather than made of datagrams.
+func isStream(conn net.Conn) bool {
+	switch conn.LocalAddr().Network() {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	default:
+		return false
+	}
+}
+
+// Close closes the connection to the syslog server.
+func (s *SyslogSink) Close(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+
+	return err
+}
+
+// priority returns the PRI part of a message of the given level.
+func (s *SyslogSink) priority(level Level) string {
+	return "<" + strconv.Itoa(int(s.facility)*8+syslogSeverity(level)) + ">"
+}
+
+// appendRFC5424 appends the entry as an RFC 5424 message, such as:
+//
+//	<14>1 2006-01-02T15:04:05.000000Z host app 42 - [fields@32473 key="value"] message
+func (s *SyslogSink) appendRFC5424(buf []byte, e Entry) []byte {
+	buf = append(buf, s.priority(e.Level)...)
+	buf = append(buf, "1 "...)
+	if e.Time.IsZero() {
+		buf = append(buf, '-')
+	} else {
+		buf = e.Time.AppendFormat(buf, "2006-01-02T15:04:05.000000Z07:00")
+	}
+	buf = append(buf, ' ')
+	buf = append(buf, syslogHeaderField(s.hostname, 255)...)
+	buf = append(buf, ' ')
+	buf = append(buf, syslogHeaderField(s.appName, 48)...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(os.Getpid()), 10)
+	buf = append(buf, " - "...)
+	buf = s.appendStructuredData(buf, e)
+	if e.Message != "" {
+		buf = append(buf, ' ')
+		buf = append(buf, truncate(e.Message, e.maxLen)...)
+	}
+
+	return buf
+}
+
+// appendStructuredData appends the logger name, caller and fields of the entry
+// as one structured data element, or "-" if there are none.
+func (s *SyslogSink) appendStructuredData(buf []byte, e Entry) []byte {
+	var params []Field
+	if e.LoggerName != "" {
+		params = append(params, String("logger", e.LoggerName))
+	}
+	if e.Caller != nil {
+		params = append(params, String("caller", e.Caller.String()))
+	}
+	params = flattenFields(params, "", e.Fields)
+
+	if len(params) == 0 {
+		return append(buf, '-')
+	}
+
+	buf = append(buf, '[')
+	buf = append(buf, sdName(s.sdID, 255)...)
+	for _, p := range params {
+		buf = append(buf, ' ')
+		buf = append(buf, sdName(p.Key, 32)...)
+		buf = append(buf, `="`...)
+		buf = appendSDValue(buf, formatValue(p.Value))
+		buf = append(buf, '"')
+	}
+
+	return append(buf, ']')
+}
+
+// appendRFC3164 appends the entry as an RFC 3164 message, such as:
+//
+//	<14>Jan  2 15:04:05 host app[42]: [db] message key=value
+func (s *SyslogSink) appendRFC3164(buf []byte, e Entry) []byte {
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	buf = append(buf, s.priority(e.Level)...)
+	buf = t.AppendFormat(buf, time.Stamp)
+	buf = append(buf, ' ')
+	buf = append(buf, syslogHeaderField(s.hostname, 255)...)
+	buf = append(buf, ' ')
+	buf = append(buf, s.appName...)
+	buf = append(buf, '[')
+	buf = strconv.AppendInt(buf, int64(os.Getpid()), 10)
+	buf = append(buf, "]: "...)
+
+	var sb strings.Builder
+	if e.LoggerName != "" {
+		sb.WriteString("[" + e.LoggerName + "] ")
+	}
+	sb.WriteString(e.Message)
+	if e.Caller != nil {
+		sb.WriteString(" caller=")
+		sb.WriteString(quoteIfNeeded(e.Caller.String()))
+	}
+	appendFieldsText(&sb, e.Fields)
+
+	return append(buf, truncate(sb.String(), e.maxLen)...)
+}
+
+// flattenFields appends the fields to flat, groups being replaced by their
+// fields with dotted keys.
+func flattenFields(flat []Field, prefix string, fields []Field) []Field {
+	for _, f := range fields {
+		if group, ok := f.Value.([]Field); ok {
+			flat = flattenFields(flat, prefix+f.Key+".", group)
+			continue
+		}
+
+		flat = append(flat, Field{Key: prefix + f.Key, Value: f.Value})
+	}
+
+	return flat
+}
+
+// syslogHeaderField returns the value as a header field of at most maxLen
+// printable ASCII characters, or "-" if it is empty.
+func syslogHeaderField(value string, maxLen int) string {
+	value = printableASCII(value, func(byte) bool { return true }, maxLen)
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
+
+// sdName returns the value as a structured data name of at most maxLen
+// characters: printable ASCII characters other than '=', ' ', ']' and '"'.
+func sdName(value string, maxLen int) string {
+	name := printableASCII(value, func(c byte) bool {
+		return c != '=' && c != ']' && c != '"'
+	}, maxLen)
+	if name == "" {
+		return "_"
+	}
+
+	return name
+}
+
+// printableASCII replaces the characters of value that are not printable
+// ASCII, or not allowed, by underscores, and cuts it to maxLen characters.
+func printableASCII(value string, allowed func(byte) bool, maxLen int) string {
+	b := []byte(value)
+	for i, c := range b {
+		if c <= ' ' || c > '~' || !allowed(c) {
+			b[i] = '_'
+		}
+	}
+
+	if len(b) > maxLen {
+		b = b[:maxLen]
+	}
+
+	return string(b)
+}
+
+// appendSDValue appends a structured data parameter value, escaping '"', '\' and ']'.
+func appendSDValue(buf []byte, value string) []byte {
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '"', '\\', ']':
+			buf = append(buf, '\\', c)
+		default:
+			buf = append(buf, c)
+		}
+	}
+
+	return buf
+}
@@ -0,0 +1,426 @@
+package pocketlog_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pschulze/pocket-sized-go/logger/pocketlog"
+)
+
+// syslogServer is an in-process stand-in for a syslog server, which sends the
+// messages it receives to a channel.
+type syslogServer struct {
+	network  string
+	address  string
+	messages chan string
+
+	// ln and conns hold the stream listener and the connections accepted so far.
+	ln    net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+// newSyslogServer listens on a local address of the network: "udp", "tcp", "unix" or "unixgram".
+func newSyslogServer(t *testing.T, network string) *syslogServer {
+	t.Helper()
+
+	srv := &syslogServer{network: network, messages: make(chan string, 10)}
+
+	switch network {
+	case "tcp", "unix":
+		address := "127.0.0.1:0"
+		if network == "unix" {
+			address = filepath.Join(t.TempDir(), "log.sock")
+		}
+
+		ln, err := net.Listen(network, address)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		t.Cleanup(func() { _ = ln.Close() })
+		srv.ln = ln
+		srv.address = ln.Addr().String()
+
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				srv.mu.Lock()
+				srv.conns = append(srv.conns, conn)
+				srv.mu.Unlock()
+
+				go srv.readFrames(conn)
+			}
+		}()
+	default:
+		address := "127.0.0.1:0"
+		if network == "unixgram" {
+			address = filepath.Join(t.TempDir(), "log.sock")
+		}
+
+		pc, err := net.ListenPacket(network, address)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		t.Cleanup(func() { _ = pc.Close() })
+		srv.address = pc.LocalAddr().String()
+
+		go func() {
+			buf := make([]byte, 64<<10)
+			for {
+				n, _, err := pc.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+				srv.messages <- string(buf[:n])
+			}
+		}()
+	}
+
+	return srv
+}
+
+// readFrames reads octet-counted messages from the connection.
+func (srv *syslogServer) readFrames(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for {
+		length, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+
+		n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		if err != nil {
+			srv.messages <- "invalid frame length " + length
+			return
+		}
+
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			return
+		}
+		srv.messages <- string(msg)
+	}
+}
+
+// stop closes the listener and the connections accepted so far.
+func (srv *syslogServer) stop() {
+	_ = srv.ln.Close()
+	srv.dropConnections()
+}
+
+// dropConnections closes the stream connections accepted so far, and returns their count.
+func (srv *syslogServer) dropConnections() int {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	for _, conn := range srv.conns {
+		_ = conn.Close()
+	}
+
+	n := len(srv.conns)
+	srv.conns = nil
+
+	return n
+}
+
+// next returns the next message received by the server.
+func (srv *syslogServer) next(t *testing.T) string {
+	t.Helper()
+
+	select {
+	case msg := <-srv.messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a message")
+		return ""
+	}
+}
+
+func TestSyslogSink_RFC5424(t *testing.T) {
+	for _, network := range []string{"udp", "tcp", "unixgram"} {
+		t.Run(network, func(t *testing.T) {
+			srv := newSyslogServer(t, network)
+
+			sink, err := pocketlog.NewSyslogSink(srv.network, srv.address,
+				pocketlog.SyslogFacility(pocketlog.FacilityLocal0),
+				pocketlog.SyslogHostname("web-1"),
+				pocketlog.SyslogAppName("api"),
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			lgr := pocketlog.New(pocketlog.LevelDebug,
+				pocketlog.WithSinks(sink),
+				pocketlog.WithClock(fixedClock),
+			).Named("db")
+			defer lgr.Close(context.Background())
+
+			lgr.Error("query failed", pocketlog.String("table", `users "main"`), pocketlog.Group("retry", pocketlog.Int("attempt", 2)))
+			lgr.Debugf(debugMessage)
+
+			pid := os.Getpid()
+			expected := []string{
+				fmt.Sprintf(`<131>1 2025-01-02T03:04:05.000000Z web-1 api %d - [fields@32473 logger="db" table="users \"main\"" retry.attempt="2"] query failed`, pid),
+				fmt.Sprintf(`<135>1 2025-01-02T03:04:05.000000Z web-1 api %d - [fields@32473 logger="db"] %s`, pid, debugMessage),
+			}
+			for _, want := range expected {
+				if got := srv.next(t); got != want {
+					t.Errorf("invalid message, expected %q, got %q", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSyslogSink_RFC3164(t *testing.T) {
+	srv := newSyslogServer(t, "udp")
+
+	sink, err := pocketlog.NewSyslogSink(srv.network, srv.address,
+		pocketlog.SyslogMessageFormat(pocketlog.SyslogRFC3164),
+		pocketlog.SyslogHostname("web-1"),
+		pocketlog.SyslogAppName("api"),
+		pocketlog.SyslogLevel(pocketlog.LevelInfo),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lgr := pocketlog.New(pocketlog.LevelTrace, pocketlog.WithSinks(sink), pocketlog.WithClock(fixedClock))
+	defer lgr.Close(context.Background())
+
+	// Below the sink's level.
+	lgr.Debugf(debugMessage)
+	lgr.Warn("disk almost full", pocketlog.Float64("usage", 0.93))
+
+	expected := fmt.Sprintf("<12>Jan  2 03:04:05 web-1 api[%d]: disk almost full usage=0.93", os.Getpid())
+	if got := srv.next(t); got != expected {
+		t.Errorf("invalid message, expected %q, got %q", expected, got)
+	}
+}
+
+func TestSyslogSink_severities(t *testing.T) {
+	srv := newSyslogServer(t, "udp")
+
+	sink, err := pocketlog.NewSyslogSink(srv.network, srv.address, pocketlog.SyslogFacility(pocketlog.FacilityDaemon))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer sink.Close(context.Background())
+
+	expected := map[pocketlog.Level]string{
+		pocketlog.LevelTrace: "<31>",
+		pocketlog.LevelDebug: "<31>",
+		pocketlog.LevelInfo:  "<30>",
+		pocketlog.LevelWarn:  "<28>",
+		pocketlog.LevelError: "<27>",
+		pocketlog.LevelPanic: "<26>",
+		pocketlog.LevelFatal: "<25>",
+	}
+	for level := pocketlog.LevelTrace; level <= pocketlog.LevelFatal; level++ {
+		if err := sink.WriteEntry(pocketlog.Entry{Level: level, Message: level.Name()}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if got := srv.next(t); !strings.HasPrefix(got, expected[level]+"1 - ") {
+			t.Errorf("invalid priority for %s, expected %q, got %q", level.Name(), expected[level], got)
+		}
+	}
+}
+
+func TestSyslogSink_reconnect(t *testing.T) {
+	srv := newSyslogServer(t, "tcp")
+
+	sink, err := pocketlog.NewSyslogSink(srv.network, srv.address, pocketlog.SyslogHostname("web-1"), pocketlog.SyslogAppName("api"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer sink.Close(context.Background())
+
+	entry := pocketlog.Entry{Level: pocketlog.LevelInfo, Message: infoMessage}
+	if err := sink.WriteEntry(entry); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	srv.next(t)
+
+	if n := srv.dropConnections(); n != 1 {
+		t.Fatalf("expected 1 connection, got %d", n)
+	}
+
+	// The first writes after the server closed the connection may be lost
+	// before the sink notices it is broken and reconnects.
+	for range 100 {
+		if err := sink.WriteEntry(entry); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		select {
+		case msg := <-srv.messages:
+			expected := fmt.Sprintf("<14>1 - web-1 api %d - - %s", os.Getpid(), infoMessage)
+			if msg != expected {
+				t.Errorf("invalid message, expected %q, got %q", expected, msg)
+			}
+			if n := srv.dropConnections(); n != 1 {
+				t.Errorf("expected 1 new connection, got %d", n)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	t.Fatal("the sink did not reconnect")
+}
+
+func TestSyslogSink_multiline(t *testing.T) {
+	for _, network := range []string{"tcp", "unix"} {
+		t.Run(network, func(t *testing.T) {
+			srv := newSyslogServer(t, network)
+
+			sink, err := pocketlog.NewSyslogSink(srv.network, srv.address, pocketlog.SyslogHostname("web-1"), pocketlog.SyslogAppName("api"))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			defer sink.Close(context.Background())
+
+			// Messages are octet-counted, so embedded newlines do not split them.
+			message := "panic: boom\n\ngoroutine 1 [running]:\nmain.main()"
+			if err := sink.WriteEntry(pocketlog.Entry{Level: pocketlog.LevelError, Message: message}); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			expected := fmt.Sprintf("<11>1 - web-1 api %d - - %s", os.Getpid(), message)
+			if got := srv.next(t); got != expected {
+				t.Errorf("invalid message, expected %q, got %q", expected, got)
+			}
+		})
+	}
+}
+
+func TestSyslogSink_reconnectBackoff(t *testing.T) {
+	srv := newSyslogServer(t, "tcp")
+
+	sink, err := pocketlog.NewSyslogSink(srv.network, srv.address)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer sink.Close(context.Background())
+
+	entry := pocketlog.Entry{Level: pocketlog.LevelInfo, Message: infoMessage}
+	if err := sink.WriteEntry(entry); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	srv.next(t)
+
+	srv.stop()
+
+	// The first writes after the server went away may be lost before the
+	// sink notices the connection is broken and fails to reconnect.
+	failed := false
+	for range 100 {
+		if err := sink.WriteEntry(entry); err != nil {
+			failed = true
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !failed {
+		t.Fatal("expected the sink to fail once the server is gone")
+	}
+
+	// Until the backoff is over, writes fail without dialing again.
+	start := time.Now()
+	err = sink.WriteEntry(entry)
+	if err == nil || !strings.Contains(err.Error(), "waiting to reconnect") {
+		t.Errorf("expected the sink to wait before reconnecting, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the write to fail fast, took %s", elapsed)
+	}
+}
+
+func TestSyslogSink_writeTimeout(t *testing.T) {
+	// The server accepts connections but never reads from them.
+	ln, err := net.Listen("unix", filepath.Join(t.TempDir(), "stalled.sock"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+
+	sink, err := pocketlog.NewSyslogSink("unix", ln.Addr().String(), pocketlog.SyslogWriteTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer sink.Close(context.Background())
+
+	// Write until the socket buffers are full and a write times out.
+	entry := pocketlog.Entry{Level: pocketlog.LevelInfo, Message: strings.Repeat("x", 64<<10)}
+	var writeErr error
+	for range 1000 {
+		if writeErr = sink.WriteEntry(entry); writeErr != nil {
+			break
+		}
+	}
+	if !errors.Is(writeErr, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected the write to time out, got %v", writeErr)
+	}
+
+	// The connection is dropped, and the sink waits before reconnecting.
+	start := time.Now()
+	err = sink.WriteEntry(entry)
+	if err == nil || !strings.Contains(err.Error(), "waiting to reconnect") {
+		t.Errorf("expected the sink to wait before reconnecting, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the write to fail fast, took %s", elapsed)
+	}
+}
+
+func TestSyslogSink_closed(t *testing.T) {
+	srv := newSyslogServer(t, "udp")
+
+	sink, err := pocketlog.NewSyslogSink(srv.network, srv.address)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := sink.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := sink.WriteEntry(pocketlog.Entry{Level: pocketlog.LevelInfo, Message: infoMessage}); err == nil {
+		t.Error("expected an error writing to a closed sink")
+	}
+}
+
+func TestNewSyslogSink_unreachable(t *testing.T) {
+	_, err := pocketlog.NewSyslogSink("unix", filepath.Join(t.TempDir(), "missing.sock"))
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,31 @@
+//go:build linux
+
+package pocketlog
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// isTerminal tells whether w is a file referring to a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	var errno syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		var termios syscall.Termios
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+	})
+
+	return err == nil && errno == 0
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package pocketlog
+
+import "io"
+
+// isTerminal reports false: terminals are only detected on Linux.
+func isTerminal(io.Writer) bool {
+	return false
+}